@@ -1,11 +1,45 @@
 package gocli
 
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimonrus/porterr"
+)
+
 const (
 	ArgumentTypeString = "string"
 	ArgumentTypeInt    = "int"
 	ArgumentTypeUint   = "uint"
 	ArgumentTypeBool   = "bool"
 	ArgumentTypeFloat  = "float"
+
+	ArgumentTypeDuration    = "duration"
+	ArgumentTypeTime        = "time"
+	ArgumentTypeStringSlice = "string[]"
+	ArgumentTypeIntSlice    = "int[]"
+	ArgumentTypeMap         = "map"
+	ArgumentTypeEnum        = "enum"
+	ArgumentTypePath        = "path"
+)
+
+const (
+	// ArgumentSourceFlag Value passed in command line
+	ArgumentSourceFlag = "flag"
+	// ArgumentSourceEnv Value from environment variable
+	ArgumentSourceEnv = "env"
+	// ArgumentSourceDefault Value from config default
+	ArgumentSourceDefault = "default"
+	// ArgumentSourceZero Value is not set
+	ArgumentSourceZero = "zero"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
 )
 
 // ArgumentMap Console app arguments
@@ -22,6 +56,18 @@ func (a ArgumentMap) ToList() (list Arguments) {
 	return
 }
 
+// GetByName get argument by name
+func (a ArgumentMap) GetByName(name string) *Argument {
+	argument, ok := a[name]
+	if !ok {
+		return nil
+	}
+	if argument.Name == "" {
+		argument.Name = name
+	}
+	return &argument
+}
+
 // Argument struct
 type Argument struct {
 	// Type of argument
@@ -32,6 +78,30 @@ type Argument struct {
 	Label string
 	// Name of argument
 	Name string
+	// Enum allowed values for enum type
+	Enum []string
+	// Default value applied when argument is not passed
+	Default interface{}
+	// Required argument must be passed, set in env or have default value
+	Required bool
+	// Min value, length or items count
+	Min *float64
+	// Max value, length or items count
+	Max *float64
+	// Pattern regular expression for string values
+	Pattern string
+	// Env name of environment variable with argument value
+	Env string
+	// source of effective value
+	source string
+}
+
+// Source Get source of effective value of parsed flag: flag, env, default or zero
+func (a Argument) Source() string {
+	if a.source == "" {
+		return ArgumentSourceZero
+	}
+	return a.source
 }
 
 // GetString Get string value of argument
@@ -64,6 +134,334 @@ func (a Argument) GetFloat() float64 {
 	return *value
 }
 
+// Int Get value as int with Get conversion rules. Zero returned if value is not compatible
+// Example: command.Option("count").Int()
+func (a Argument) Int() int64 {
+	value, _ := getValue[int64](a)
+	return value
+}
+
+// IntOr Get value as int or default value
+func (a Argument) IntOr(def int64) int64 {
+	if value, e := a.AsInt(); e == nil {
+		return value
+	}
+	return def
+}
+
+// UintOr Get value as uint or default value
+func (a Argument) UintOr(def uint64) uint64 {
+	if value, e := a.AsUint(); e == nil {
+		return value
+	}
+	return def
+}
+
+// FloatOr Get value as float or default value
+func (a Argument) FloatOr(def float64) float64 {
+	if value, e := a.AsFloat(); e == nil {
+		return value
+	}
+	return def
+}
+
+// BoolOr Get value as bool or default value
+func (a Argument) BoolOr(def bool) bool {
+	if value, e := a.AsBool(); e == nil {
+		return value
+	}
+	return def
+}
+
+// StringOr Get value as string or default value
+func (a Argument) StringOr(def string) string {
+	if value, e := a.AsString(); e == nil {
+		return value
+	}
+	return def
+}
+
+// AsInt Get value as int. Uint, integral float and numeric string are converted
+func (a Argument) AsInt() (int64, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return 0, e
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() <= math.MaxInt64 {
+			return int64(v.Uint()), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+	case reflect.String:
+		if value, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return value, nil
+		}
+	}
+	return 0, a.typeError(v, ArgumentTypeInt)
+}
+
+// AsUint Get value as uint. Non-negative int, integral float and numeric string are converted
+func (a Argument) AsUint() (uint64, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return 0, e
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() >= 0 {
+			return uint64(v.Int()), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == math.Trunc(f) && f >= 0 && f < math.MaxUint64 {
+			return uint64(f), nil
+		}
+	case reflect.String:
+		if value, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return value, nil
+		}
+	}
+	return 0, a.typeError(v, ArgumentTypeUint)
+}
+
+// AsFloat Get value as float. Int, uint and numeric string are converted
+func (a Argument) AsFloat() (float64, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return 0, e
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		if value, err := strconv.ParseFloat(v.String(), 64); err == nil {
+			return value, nil
+		}
+	}
+	return 0, a.typeError(v, ArgumentTypeFloat)
+}
+
+// AsBool Get value as bool. Bool string is converted
+func (a Argument) AsBool() (bool, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return false, e
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		if value, err := strconv.ParseBool(v.String()); err == nil {
+			return value, nil
+		}
+	}
+	return false, a.typeError(v, ArgumentTypeBool)
+}
+
+// AsString Get value as string. Numbers and bool are formatted
+func (a Argument) AsString() (string, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return "", e
+	}
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	case timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice, reflect.Map:
+		if items, e := a.AsStrings(); e == nil {
+			return strings.Join(items, ArgumentListDelimiter), nil
+		}
+		if m, e := a.AsMap(); e == nil {
+			return renderMap(m), nil
+		}
+	}
+	return "", a.typeError(v, ArgumentTypeString)
+}
+
+// DurationOr Get value as duration or default value
+func (a Argument) DurationOr(def time.Duration) time.Duration {
+	if value, e := a.AsDuration(); e == nil {
+		return value
+	}
+	return def
+}
+
+// TimeOr Get value as time or default value
+func (a Argument) TimeOr(def time.Time) time.Time {
+	if value, e := a.AsTime(); e == nil {
+		return value
+	}
+	return def
+}
+
+// AsDuration Get value as duration. Duration string is parsed, integers are nanoseconds
+func (a Argument) AsDuration() (time.Duration, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return 0, e
+	}
+	if v.Kind() == reflect.String {
+		if value, err := time.ParseDuration(v.String()); err == nil {
+			return value, nil
+		}
+		return 0, a.typeError(v, ArgumentTypeDuration)
+	}
+	value, e := a.AsInt()
+	if e != nil {
+		return 0, a.typeError(v, ArgumentTypeDuration)
+	}
+	return time.Duration(value), nil
+}
+
+// AsTime Get value as time. RFC 3339 string is parsed
+func (a Argument) AsTime() (time.Time, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return time.Time{}, e
+	}
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time), nil
+	case v.Kind() == reflect.String:
+		if value, err := time.Parse(time.RFC3339, v.String()); err == nil {
+			return value, nil
+		}
+	}
+	return time.Time{}, a.typeError(v, ArgumentTypeTime)
+}
+
+// AsStrings Get value as string list. Comma separated string is split, list items are formatted
+func (a Argument) AsStrings() ([]string, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return nil, e
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return splitList(v.String()), nil
+	case reflect.Slice, reflect.Array:
+		result := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, e := Argument{Name: a.Name, Value: v.Index(i).Interface()}.AsString()
+			if e != nil {
+				return nil, a.typeError(v, ArgumentTypeStringSlice)
+			}
+			result[i] = item
+		}
+		return result, nil
+	}
+	return nil, a.typeError(v, ArgumentTypeStringSlice)
+}
+
+// AsInts Get value as int list. Comma separated string is split, list items are converted
+func (a Argument) AsInts() ([]int64, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return nil, e
+	}
+	var items []interface{}
+	switch v.Kind() {
+	case reflect.String:
+		for _, item := range splitList(v.String()) {
+			items = append(items, item)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	default:
+		return nil, a.typeError(v, ArgumentTypeIntSlice)
+	}
+	result := make([]int64, len(items))
+	for i := range items {
+		item, e := Argument{Name: a.Name, Value: items[i]}.AsInt()
+		if e != nil {
+			return nil, a.typeError(v, ArgumentTypeIntSlice)
+		}
+		result[i] = item
+	}
+	return result, nil
+}
+
+// AsMap Get value as map. k=v,k2=v2 string is parsed
+func (a Argument) AsMap() (map[string]string, porterr.IError) {
+	v, e := a.reflectValue()
+	if e != nil {
+		return nil, e
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if value, e := parseMap(v.String()); e == nil {
+			return value, nil
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		result := make(map[string]string, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, e := Argument{Name: a.Name, Value: iter.Value().Interface()}.AsString()
+			if e != nil {
+				return nil, a.typeError(v, ArgumentTypeMap)
+			}
+			result[iter.Key().String()] = item
+		}
+		return result, nil
+	}
+	return nil, a.typeError(v, ArgumentTypeMap)
+}
+
+// reflectValue Get dereferenced argument value
+func (a Argument) reflectValue() (reflect.Value, porterr.IError) {
+	v := reflect.ValueOf(a.Value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || ((v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil()) {
+		return v, porterr.New(porterr.PortErrorType, "argument "+a.Name+": value is not set")
+	}
+	return v, nil
+}
+
+// formatFloat render float without trailing zeros
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// typeError argument type error
+func (a Argument) typeError(v reflect.Value, target string) porterr.IError {
+	return porterr.NewF(porterr.PortErrorType, "argument %s: %v (%s) is not compatible with %s", a.Name, v.Interface(), v.Kind(), target)
+}
+
 // Arguments list of arguments
 type Arguments []Argument
 
@@ -62,3 +62,61 @@ func TestArgumentMap_ToList(t *testing.T) {
 		t.Fatal("wrong logic")
 	}
 }
+
+func TestArgument_As(t *testing.T) {
+	t.Run("convert", func(t *testing.T) {
+		value, e := Argument{Value: gohelp.Ptr(uint64(1))}.AsInt()
+		if e != nil || value != 1 {
+			t.Fatal("uint must be converted to int")
+		}
+		unsigned, e := Argument{Value: gohelp.Ptr(int64(5))}.AsUint()
+		if e != nil || unsigned != 5 {
+			t.Fatal("int must be converted to uint")
+		}
+		float, e := Argument{Value: gohelp.Ptr(10)}.AsFloat()
+		if e != nil || float != 10 {
+			t.Fatal("int must be converted to float")
+		}
+		value, e = Argument{Value: gohelp.Ptr(2.0)}.AsInt()
+		if e != nil || value != 2 {
+			t.Fatal("integral float must be converted to int")
+		}
+		str, e := Argument{Value: gohelp.Ptr(33.44)}.AsString()
+		if e != nil || str != "33.44" {
+			t.Fatal("float must be formatted")
+		}
+		b, e := Argument{Value: gohelp.Ptr("true")}.AsBool()
+		if e != nil || !b {
+			t.Fatal("bool string must be converted")
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if _, e := (Argument{Name: "count"}).AsInt(); e == nil {
+			t.Fatal("nil value must be error")
+		}
+		var nilPointer *int64
+		if _, e := (Argument{Name: "count", Value: nilPointer}).AsInt(); e == nil {
+			t.Fatal("nil pointer must be error")
+		}
+		if _, e := (Argument{Name: "count", Value: gohelp.Ptr(-1)}).AsUint(); e == nil {
+			t.Fatal("negative int must be error")
+		}
+		if _, e := (Argument{Name: "count", Value: gohelp.Ptr(1.5)}).AsInt(); e == nil {
+			t.Fatal("fractional float must be error")
+		}
+		if _, e := (Argument{Name: "count", Value: gohelp.Ptr(true)}).AsFloat(); e == nil {
+			t.Fatal("bool must be error")
+		}
+	})
+	t.Run("default", func(t *testing.T) {
+		if (Argument{}).IntOr(10) != 10 {
+			t.Fatal("default must be returned")
+		}
+		if (Argument{Value: gohelp.Ptr("abc")}).FloatOr(1.5) != 1.5 {
+			t.Fatal("default must be returned")
+		}
+		if (Argument{}).StringOr("none") != "none" {
+			t.Fatal("default must be returned")
+		}
+	})
+}
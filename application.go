@@ -1,6 +1,12 @@
 package gocli
 
-import "github.com/dimonrus/porterr"
+import (
+	"context"
+	"flag"
+	"io"
+
+	"github.com/dimonrus/porterr"
+)
 
 // Application interface
 type Application interface {
@@ -14,10 +20,30 @@ type Application interface {
 	SetConfig(cfg interface{}) Application
 	// ParseConfig Parse config
 	ParseConfig(env string) Application
+	// ParseConfigE Parse config and return error instead of fatal error
+	ParseConfigE(env string) porterr.IError
+	// SetStrictConfig Enable errors for config keys not defined in config struct
+	SetStrictConfig(strict bool)
+	// SetConfigDecoder Register decoder for config file extension
+	SetConfigDecoder(extension string, decoder ConfigDecoder)
+	// SetEncryptionKeyEnv Set environment variable with encryption key
+	SetEncryptionKeyEnv(name string)
+	// SetEncryptionKeyFile Set file with encryption key
+	SetEncryptionKeyFile(path string)
+	// WatchConfig Reload config on config files change until context is done
+	WatchConfig(ctx context.Context, callback ConfigChangeHandler) porterr.IError
+	// EffectiveConfig Render merged config as yaml
+	EffectiveConfig() ([]byte, porterr.IError)
+	// SetMergeStrategy Set merge strategy for config key path
+	SetMergeStrategy(path string, strategy string)
 	// Start run application
 	Start(port string, callback func(command *Command)) porterr.IError
+	// StartContext run application until context is done
+	StartContext(ctx context.Context, port string, callback func(command *Command)) porterr.IError
 	// FatalError Behaviour for fatal errors
 	FatalError(err error)
+	// SetFatalHandler Set fatal error handler
+	SetFatalHandler(handler FatalHandler)
 	// GetLogger Get Logger
 	GetLogger() Logger
 	// SetLogger set custom logger
@@ -29,5 +55,25 @@ type Application interface {
 	// FailMessage Fail log message with command repeat
 	FailMessage(message string, command ...*Command)
 	// ParseFlags Parse console flags
-	ParseFlags(args ArgumentMap)
+	ParseFlags(args ArgumentMap) porterr.IError
+	// ParseFlagsFrom Parse flags from argument list
+	ParseFlagsFrom(args []string, argMap ArgumentMap) porterr.IError
+	// ParseFlagsInto Parse flags into struct fields and argument maps
+	ParseFlagsInto(v interface{}, argMap ...ArgumentMap) porterr.IError
+	// SetEnvPrefix Bind arguments to prefixed environment variables
+	SetEnvPrefix(prefix string)
+	// SetFlagErrorHandling Set flag parse error handling mode
+	SetFlagErrorHandling(handling flag.ErrorHandling)
+	// AddMode Register application mode
+	AddMode(mode Mode) Application
+	// GetMode Get registered mode by name
+	GetMode(name string) *Mode
+	// Run Run mode chosen by first command line argument
+	Run() porterr.IError
+	// RunFrom Run mode chosen by first argument of list
+	RunFrom(args []string) porterr.IError
+	// Usage Render usage of all application modes or of parsed arguments without modes
+	Usage(w io.Writer, format string)
+	// SetUsageFormat Set usage format for -h output
+	SetUsageFormat(format string)
 }
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -20,12 +21,20 @@ var (
 	ignored  = []rune{' ', '\n', '\t', '\r'}
 	assigned = '='
 	dash     = '-'
+
+	singleQuote byte = '\''
+	doubleQuote byte = '"'
+	escape      byte = '\\'
 )
 
 // Command is an argument list
 type Command struct {
 	// list of arguments
 	arguments Arguments
+	// named options
+	options ArgumentMap
+	// arguments without name
+	positionals Arguments
 	// net connection
 	connection net.Conn
 	// original command
@@ -75,6 +84,30 @@ func (c *Command) Arguments() Arguments {
 	return c.arguments
 }
 
+// Options Named command options. Example: -app=script name=migration --class one
+func (c *Command) Options() ArgumentMap {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.options
+}
+
+// Option Get named option. Option without value returned if option is not present
+func (c *Command) Option(name string) Argument {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	if option, ok := c.options[name]; ok {
+		return option
+	}
+	return Argument{Name: name}
+}
+
+// Positionals command arguments without name
+func (c *Command) Positionals() Arguments {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.positionals
+}
+
 // GetOrigin Get origin command
 func (c *Command) GetOrigin() string {
 	c.m.RLock()
@@ -94,73 +127,223 @@ func (c *Command) String() string {
 }
 
 // ParseCommand Parse command
+// Arguments parsed before syntax error are returned. Use ParseCommandE to check syntax
 func ParseCommand(command []byte) *Command {
-	var isIgnored, isAssignee bool
-	var word = make([]byte, 0, 16)
-	var k int
+	cmd, _ := ParseCommandE(command)
+	return cmd
+}
+
+// ParseCommandE Parse command with syntax check
+// Supports single and double quoted words and backslash escapes
+// \n, \t and \r are converted in double quotes only. Backslash outside quotes escapes next char literally
+func ParseCommandE(command []byte) (*Command, porterr.IError) {
+	tokens, e := tokenizeCommand(command)
 	cmd := Command{
 		origin:    command,
-		arguments: make(Arguments, 8),
+		arguments: make(Arguments, len(tokens)),
 	}
-	var l = len(command) - 1
-	for j, c := range command {
-		isIgnored, isAssignee = false, false
-		for i := 0; i < len(ignored); i++ {
-			if ignored[i] == rune(c) {
-				isIgnored = true
-				break
-			}
-			if assigned == rune(c) {
-				isAssignee = true
-			}
+	for i := range tokens {
+		cmd.arguments[i] = parseArgument(string(tokens[i].word), tokens[i].quoted)
+	}
+	cmd.options, cmd.positionals = bindOptions(tokens, cmd.arguments)
+	return &cmd, e
+}
+
+// bindOptions split arguments into named options and positionals
+// name=value, -name=value, --name value are options. Dashed word without value is bool option
+func bindOptions(tokens []commandToken, arguments Arguments) (options ArgumentMap, positionals Arguments) {
+	options = make(ArgumentMap)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if !token.assigned && token.dashes == 0 {
+			positionals = append(positionals, arguments[i])
+			continue
 		}
-		if len(word) == 0 && rune(c) == dash {
-			isIgnored = true
+		name := string(token.word)
+		var option Argument
+		hasNext := i+1 < len(tokens)
+		switch {
+		case token.assigned && hasNext:
+			i++
+			option = arguments[i]
+		case token.assigned:
+			option = parseArgument("", true)
+		case hasNext && !tokens[i+1].assigned && tokens[i+1].dashes == 0:
+			i++
+			option = arguments[i]
+		default:
+			option = parseArgument("true", false)
 		}
-		if !isIgnored && !isAssignee {
-			word = append(word, c)
-			if j != l {
-				continue
+		option.Name = name
+		options[name] = option
+	}
+	return
+}
+
+// commandToken parsed command word
+type commandToken struct {
+	// word without quotes and escapes
+	word []byte
+	// word contains quoted part
+	quoted bool
+	// count of leading dashes
+	dashes int
+	// word followed by assignee
+	assigned bool
+}
+
+// tokenizeCommand split command into words
+func tokenizeCommand(command []byte) ([]commandToken, porterr.IError) {
+	var tokens = make([]commandToken, 0, 8)
+	var started, quoted, value bool
+	var quote byte
+	var begin, dashes int
+	// all words share one buffer
+	var word = make([]byte, 0, len(command))
+	flush := func(assigned bool) {
+		if started {
+			tokens = append(tokens, commandToken{
+				word:     word[begin:len(word):len(word)],
+				quoted:   quoted,
+				dashes:   dashes,
+				assigned: assigned,
+			})
+		}
+		begin, started, quoted, dashes = len(word), false, false, 0
+		// word right after assignee is value, its dashes are kept
+		value = assigned
+	}
+	for j := 0; j < len(command); j++ {
+		c := command[j]
+		if quote != 0 {
+			switch {
+			case c == quote:
+				quote = 0
+			case c == escape && quote == doubleQuote && j+1 < len(command):
+				j++
+				word = append(word, unescape(command[j]))
+			default:
+				word = append(word, c)
 			}
-		} else {
-			if len(word) == 0 {
-				continue
+			continue
+		}
+		switch {
+		case c == singleQuote || c == doubleQuote:
+			quote, started, quoted = c, true, true
+		case c == escape:
+			if j+1 == len(command) {
+				return tokens, porterr.NewF(porterr.PortErrorCommand, "Unterminated escape at position %d", j)
 			}
+			// escaped char outside quotes is taken literally
+			j++
+			word = append(word, command[j])
+			started = true
+		case isIgnored(c):
+			flush(false)
+		case rune(c) == assigned:
+			flush(true)
+		case !started && !value && rune(c) == dash:
+			dashes++
+		default:
+			word = append(word, c)
+			started = true
 		}
-		if k >= cap(cmd.arguments) {
-			cmd.arguments = append(cmd.arguments, make(Arguments, 8)...)
+	}
+	if quote != 0 {
+		return tokens, porterr.NewF(porterr.PortErrorCommand, "Unterminated quote %c", quote)
+	}
+	flush(false)
+	return tokens, nil
+}
+
+// isIgnored check if char is word separator
+func isIgnored(c byte) bool {
+	for i := 0; i < len(ignored); i++ {
+		if ignored[i] == rune(c) {
+			return true
 		}
-		isUint, isInt, isFloat, isBool, isString := gohelp.CheckTypeOf(word)
-		value := string(word)
+	}
+	return false
+}
+
+// splitCommands split line into commands by CommandDelimiter outside quotes and escapes
+// Unterminated quote keeps rest of line in last command so ParseCommandE reports it
+func splitCommands(line []byte) [][]byte {
+	var commands [][]byte
+	var quote byte
+	var begin int
+	for j := 0; j < len(line); j++ {
+		c := line[j]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case c == escape && (quote == 0 || quote == doubleQuote):
+			j++
+		case quote != 0:
+		case c == singleQuote || c == doubleQuote:
+			quote = c
+		case c == CommandDelimiter[0]:
+			commands = append(commands, line[begin:j])
+			begin = j + 1
+		}
+	}
+	return append(commands, line[begin:])
+}
+
+// unescape convert escaped char of double quoted word
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	}
+	return c
+}
+
+// parseArgument create argument with detected type
+// Quoted values are always strings
+func parseArgument(value string, quoted bool) (argument Argument) {
+	argument.Name = value
+	if !quoted {
+		isUint, isInt, isFloat, isBool, _ := gohelp.CheckTypeOf([]byte(value))
 		switch true {
 		case isUint:
 			if valueUint64, err := strconv.ParseUint(value, 10, 64); err == nil {
-				cmd.arguments[k].Type = ArgumentTypeUint
-				cmd.arguments[k].Value = &valueUint64
+				argument.Type = ArgumentTypeUint
+				argument.Value = &valueUint64
+				return
 			}
 		case isInt:
 			if valueInt64, err := strconv.ParseInt(value, 10, 64); err == nil {
-				cmd.arguments[k].Type = ArgumentTypeInt
-				cmd.arguments[k].Value = &valueInt64
+				argument.Type = ArgumentTypeInt
+				argument.Value = &valueInt64
+				return
 			}
 		case isFloat:
 			if valueFloat64, err := strconv.ParseFloat(value, 64); err == nil {
-				cmd.arguments[k].Type = ArgumentTypeFloat
-				cmd.arguments[k].Value = &valueFloat64
+				argument.Type = ArgumentTypeFloat
+				argument.Value = &valueFloat64
+				return
 			}
 		case isBool:
 			if valueBool, err := strconv.ParseBool(value); err == nil {
-				cmd.arguments[k].Type = ArgumentTypeBool
-				cmd.arguments[k].Value = &valueBool
+				argument.Type = ArgumentTypeBool
+				argument.Value = &valueBool
+				return
+			}
+		}
+		if len(value) > 1 && (value[0] >= '0' && value[0] <= '9' || value[0] == '.') {
+			if valueDuration, err := time.ParseDuration(value); err == nil {
+				argument.Type = ArgumentTypeDuration
+				argument.Value = &valueDuration
+				return
 			}
-		case isString:
-			cmd.arguments[k].Type = ArgumentTypeString
-			cmd.arguments[k].Value = &value
 		}
-		cmd.arguments[k].Name = value
-		k++
-		word = word[:0]
 	}
-	cmd.arguments = cmd.arguments[:k]
-	return &cmd
+	argument.Type = ArgumentTypeString
+	argument.Value = &value
+	return
 }
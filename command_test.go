@@ -2,6 +2,7 @@ package gocli
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -59,7 +60,7 @@ func TestParseCommand2(t *testing.T) {
 	if args[1].GetString() != "set" {
 		t.Fatal("wrong parser set")
 	}
-	if args[3].GetInt() != 1 {
+	if count, e := args[3].AsInt(); e != nil || count != 1 {
 		t.Fatal("wrong parser count number")
 	}
 }
@@ -77,3 +78,163 @@ func BenchmarkParseCommand(b *testing.B) {
 	}
 	b.ReportAllocs()
 }
+
+func TestParseCommandE(t *testing.T) {
+	t.Run("quoted", func(t *testing.T) {
+		command, e := ParseCommandE([]byte(`send message="hello world" token='a=b==' count="10"`))
+		if e != nil {
+			t.Fatal(e)
+		}
+		args := command.Arguments()
+		if len(args) != 7 {
+			t.Fatal("wrong command parsing", command.String())
+		}
+		if args[2].GetString() != "hello world" {
+			t.Fatal("wrong double quoted value")
+		}
+		if args[4].GetString() != "a=b==" {
+			t.Fatal("wrong single quoted value")
+		}
+		if args[6].Type != ArgumentTypeString || args[6].GetString() != "10" {
+			t.Fatal("quoted value must be string")
+		}
+	})
+	t.Run("escape", func(t *testing.T) {
+		command, e := ParseCommandE([]byte(`url=http://host/?a\=1 text="say \"hi\"\n" path=my\ dir '\n' "-x"`))
+		if e != nil {
+			t.Fatal(e)
+		}
+		args := command.Arguments()
+		if len(args) != 8 {
+			t.Fatal("wrong command parsing", command.String())
+		}
+		if args[1].GetString() != "http://host/?a=1" {
+			t.Fatal("wrong escaped assignee", args[1].GetString())
+		}
+		if args[3].GetString() != "say \"hi\"\n" {
+			t.Fatal("wrong escaped quotes", args[3].GetString())
+		}
+		if args[5].GetString() != "my dir" {
+			t.Fatal("wrong escaped space")
+		}
+		if args[6].GetString() != `\n` {
+			t.Fatal("single quoted must be literal")
+		}
+		if args[7].GetString() != "-x" {
+			t.Fatal("quoted dash must be kept")
+		}
+	})
+	t.Run("unquoted escape", func(t *testing.T) {
+		command, e := ParseCommandE([]byte(`copy C:\dir\new C:\\dir \t "\t"`))
+		if e != nil {
+			t.Fatal(e)
+		}
+		args := command.Arguments()
+		if len(args) != 5 {
+			t.Fatal("wrong command parsing", command.String())
+		}
+		if args[1].GetString() != "C:dirnew" {
+			t.Fatal("escaped char outside quotes must be literal", args[1].GetString())
+		}
+		if args[2].GetString() != `C:\dir` {
+			t.Fatal("escaped backslash must be kept", args[2].GetString())
+		}
+		if args[3].GetString() != "t" {
+			t.Fatal("unquoted escape must not be converted", args[3].GetString())
+		}
+		if args[4].GetString() != "\t" {
+			t.Fatal("double quoted escape must be converted", args[4].GetString())
+		}
+	})
+	t.Run("unterminated", func(t *testing.T) {
+		command, e := ParseCommandE([]byte(`send message="hello world`))
+		if e == nil {
+			t.Fatal("must be unterminated quote error")
+		}
+		if len(command.Arguments()) != 2 {
+			t.Fatal("unterminated word must be skipped")
+		}
+		_, e = ParseCommandE([]byte(`send \`))
+		if e == nil {
+			t.Fatal("must be unterminated escape error")
+		}
+	})
+}
+
+func TestCommand_Options(t *testing.T) {
+	command := ParseCommand([]byte("-app=script name=migration --class one count=10 --force run now"))
+	options := command.Options()
+	if len(options) != 5 {
+		t.Fatal("wrong options count", len(options))
+	}
+	if command.Option("app").StringOr("") != "script" {
+		t.Fatal("wrong dash assignee option")
+	}
+	if command.Option("name").StringOr("") != "migration" {
+		t.Fatal("wrong assignee option")
+	}
+	if command.Option("class").StringOr("") != "one" {
+		t.Fatal("wrong dash option with value")
+	}
+	if command.Option("count").IntOr(0) != 10 || command.Option("count").Int() != 10 {
+		t.Fatal("wrong typed option")
+	}
+	if command.Option("force").StringOr("") != "run" {
+		t.Fatal("dashed option must take next word")
+	}
+	if command.Option("unknown").Value != nil || command.Option("unknown").Int() != 0 {
+		t.Fatal("unknown option must be empty")
+	}
+	positionals := command.Positionals()
+	if len(positionals) != 1 || positionals[0].StringOr("") != "now" {
+		t.Fatal("wrong positionals")
+	}
+	if len(command.Arguments()) != 11 {
+		t.Fatal("flat arguments must be kept")
+	}
+
+	command = ParseCommand([]byte("-consumer stop=all --verbose"))
+	if !command.Option("consumer").BoolOr(false) || !command.Option("verbose").BoolOr(false) {
+		t.Fatal("dashed option without value must be bool")
+	}
+	if command.Option("stop").StringOr("") != "all" {
+		t.Fatal("wrong assignee after flag")
+	}
+	if len(command.Positionals()) != 0 {
+		t.Fatal("wrong positionals")
+	}
+
+	command = ParseCommand([]byte("-count=-5 offset=--2 name=-x"))
+	if command.Option("count").IntOr(0) != -5 {
+		t.Fatal("negative option value must keep sign", command.Option("count").Value)
+	}
+	if _, e := command.Option("count").AsUint(); e == nil {
+		t.Fatal("negative option value must not be uint")
+	}
+	if command.Option("offset").StringOr("") != "--2" || command.Option("name").StringOr("") != "-x" {
+		t.Fatal("option value must be kept as written")
+	}
+	if len(command.Options()) != 3 || len(command.Positionals()) != 0 {
+		t.Fatal("dashed value must not be option")
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	commands := splitCommands([]byte(`say message="a;b" text='c;d' e\;f; show`))
+	if len(commands) != 2 {
+		t.Fatal("delimiter in quotes must be skipped", len(commands))
+	}
+	command, e := ParseCommandE(commands[0])
+	if e != nil {
+		t.Fatal(e)
+	}
+	if command.Option("message").GetString() != "a;b" || command.Option("text").GetString() != "c;d" {
+		t.Fatal("wrong quoted option")
+	}
+	if command.Positionals()[1].GetString() != "e;f" {
+		t.Fatal("wrong escaped delimiter", command.Positionals()[1].GetString())
+	}
+	if strings.TrimSpace(string(commands[1])) != "show" {
+		t.Fatal("wrong second command", string(commands[1]))
+	}
+}
@@ -0,0 +1,199 @@
+package gocli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimonrus/porterr"
+)
+
+const (
+	// ModeCompletion Built-in hidden mode printing shell completion script
+	ModeCompletion = "completion"
+
+	CompletionShellBash = "bash"
+	CompletionShellZsh  = "zsh"
+	CompletionShellFish = "fish"
+)
+
+// completionMode built-in mode. Usage: myservice completion <shell>
+func (a *DNApp) completionMode() Mode {
+	return Mode{
+		Name:        ModeCompletion,
+		Description: "Print shell completion script. Usage: completion <bash|zsh|fish>",
+		Hidden:      true,
+		Runner: func(args ...Argument) porterr.IError {
+			var shell string
+			if a.flags != nil && a.flags.NArg() > 0 {
+				shell = a.flags.Arg(0)
+			}
+			return a.Completion(os.Stdout, shell)
+		},
+	}
+}
+
+// Completion Write completion script for shell generated from modes and arguments
+func (a *DNApp) Completion(w io.Writer, shell string) porterr.IError {
+	name := filepath.Base(os.Args[0])
+	var groups []UsageGroup
+	for _, mode := range a.modes {
+		if !mode.Hidden {
+			groups = append(groups, UsageGroup{Name: mode.Name, Description: mode.Description, Arguments: mode.Arguments})
+		}
+	}
+	root := UsageGroup{Arguments: a.arguments}
+	switch shell {
+	case CompletionShellBash:
+		writeBashCompletion(w, name, root, groups)
+	case CompletionShellZsh:
+		writeZshCompletion(w, name, root, groups)
+	case CompletionShellFish:
+		writeFishCompletion(w, name, root, groups)
+	default:
+		return porterr.New(porterr.PortErrorArgument, "completion shell: "+shell+" is not supported. Use bash, zsh or fish")
+	}
+	return nil
+}
+
+// completionFunction shell function name for program
+func completionFunction(name string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// writeBashCompletion bash completion script
+func writeBashCompletion(w io.Writer, name string, root UsageGroup, groups []UsageGroup) {
+	function := completionFunction(name)
+	writeBashValues := func(indent string, group UsageGroup) {
+		var flags []string
+		for _, key := range group.Arguments.keys() {
+			argument := group.Arguments[key]
+			flags = append(flags, "-"+key)
+			switch {
+			case argument.Type == ArgumentTypeEnum:
+				_, _ = fmt.Fprintf(w, "%s    -%s|--%s) COMPREPLY=( $(compgen -W %s -- \"$cur\") ); return 0 ;;\n",
+					indent, key, key, shellQuote(strings.Join(argument.Enum, " ")))
+			case argument.Type == ArgumentTypePath:
+				_, _ = fmt.Fprintf(w, "%s    -%s|--%s) COMPREPLY=( $(compgen -f -- \"$cur\") ); return 0 ;;\n", indent, key, key)
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%sesac\n%sCOMPREPLY=( $(compgen -W %s -- \"$cur\") )\n", indent, indent, shellQuote(strings.Join(flags, " ")))
+	}
+	_, _ = fmt.Fprintf(w, "# bash completion for %s\n%s() {\n", name, function)
+	_, _ = fmt.Fprint(w, "    local cur prev\n    COMPREPLY=()\n    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	_, _ = fmt.Fprint(w, "    if [ \"$prev\" = \"=\" ]; then\n        prev=\"${COMP_WORDS[COMP_CWORD-2]}\"\n    fi\n")
+	if len(groups) == 0 {
+		_, _ = fmt.Fprint(w, "    case \"$prev\" in\n")
+		writeBashValues("    ", root)
+	} else {
+		var modes []string
+		for _, group := range groups {
+			modes = append(modes, group.Name)
+		}
+		_, _ = fmt.Fprintf(w, "    if [ \"$COMP_CWORD\" -eq 1 ]; then\n        COMPREPLY=( $(compgen -W %s -- \"$cur\") )\n        return 0\n    fi\n", shellQuote(strings.Join(modes, " ")))
+		_, _ = fmt.Fprint(w, "    case \"${COMP_WORDS[1]}\" in\n")
+		for _, group := range groups {
+			_, _ = fmt.Fprintf(w, "        %s)\n            case \"$prev\" in\n", group.Name)
+			writeBashValues("            ", group)
+			_, _ = fmt.Fprint(w, "            ;;\n")
+		}
+		_, _ = fmt.Fprint(w, "    esac\n")
+	}
+	_, _ = fmt.Fprintf(w, "}\ncomplete -F %s %s\n", function, name)
+}
+
+// writeZshCompletion zsh completion script
+func writeZshCompletion(w io.Writer, name string, root UsageGroup, groups []UsageGroup) {
+	function := completionFunction(name)
+	writeZshArguments := func(indent string, group UsageGroup) {
+		_, _ = fmt.Fprintf(w, "%s_arguments", indent)
+		for _, key := range group.Arguments.keys() {
+			argument := group.Arguments[key]
+			spec := "-" + key + "[" + zshEscape(argument.Label) + "]"
+			switch argument.Type {
+			case ArgumentTypeBool:
+			case ArgumentTypeEnum:
+				spec += ":" + key + ":(" + strings.Join(argument.Enum, " ") + ")"
+			case ArgumentTypePath:
+				spec += ":" + key + ":_files"
+			default:
+				spec += ":" + key + ":"
+			}
+			_, _ = fmt.Fprintf(w, " \\\n%s    %s", indent, shellQuote(spec))
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintf(w, "#compdef %s\n%s() {\n", name, function)
+	if len(groups) == 0 {
+		writeZshArguments("    ", root)
+	} else {
+		_, _ = fmt.Fprint(w, "    local -a modes\n    modes=(")
+		for _, group := range groups {
+			_, _ = fmt.Fprintf(w, "\n        %s", shellQuote(group.Name+":"+zshEscape(group.Description)))
+		}
+		_, _ = fmt.Fprint(w, "\n    )\n    if (( CURRENT == 2 )); then\n        _describe 'mode' modes\n        return\n    fi\n")
+		_, _ = fmt.Fprint(w, "    case $words[2] in\n")
+		for _, group := range groups {
+			_, _ = fmt.Fprintf(w, "        %s)\n            shift words\n            (( CURRENT-- ))\n", group.Name)
+			writeZshArguments("            ", group)
+			_, _ = fmt.Fprint(w, "            ;;\n")
+		}
+		_, _ = fmt.Fprint(w, "    esac\n")
+	}
+	_, _ = fmt.Fprintf(w, "}\ncompdef %s %s\n", function, name)
+}
+
+// writeFishCompletion fish completion script
+func writeFishCompletion(w io.Writer, name string, root UsageGroup, groups []UsageGroup) {
+	writeFishArguments := func(condition string, group UsageGroup) {
+		for _, key := range group.Arguments.keys() {
+			argument := group.Arguments[key]
+			line := "complete -c " + name + condition + " -o " + key
+			if argument.Label != "" {
+				line += " -d " + shellQuote(argument.Label)
+			}
+			switch argument.Type {
+			case ArgumentTypeBool:
+			case ArgumentTypeEnum:
+				line += " -r -a " + shellQuote(strings.Join(argument.Enum, " "))
+			case ArgumentTypePath:
+				line += " -r -F"
+			default:
+				line += " -r"
+			}
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "# fish completion for %s\ncomplete -c %s -f\n", name, name)
+	if len(groups) == 0 {
+		writeFishArguments("", root)
+		return
+	}
+	for _, group := range groups {
+		line := "complete -c " + name + " -n '__fish_use_subcommand' -a " + group.Name
+		if group.Description != "" {
+			line += " -d " + shellQuote(group.Description)
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+	for _, group := range groups {
+		writeFishArguments(" -n '__fish_seen_subcommand_from "+group.Name+"'", group)
+	}
+}
+
+// shellQuote quote string in single quotes
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshEscape escape zsh spec special chars
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
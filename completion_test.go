@@ -0,0 +1,48 @@
+package gocli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDNApp_Completion(t *testing.T) {
+	app := &DNApp{}
+	app.AddMode(Mode{
+		Name:        "consumer",
+		Description: "Start consumer",
+		Arguments: ArgumentMap{
+			"name":   {Type: ArgumentTypeString, Label: "consumer name"},
+			"mode":   {Type: ArgumentTypeEnum, Label: "run mode", Enum: []string{"sync", "async"}},
+			"config": {Type: ArgumentTypePath, Label: "config file"},
+			"force":  {Type: ArgumentTypeBool, Label: "don't wait"},
+		},
+	}).AddMode(Mode{Name: "web", Description: "Start web server"})
+	app.AddMode(Mode{Name: "secret", Hidden: true})
+
+	cases := map[string][]string{
+		CompletionShellBash: {"complete -F", "'consumer web'", "-mode|--mode) COMPREPLY=( $(compgen -W 'sync async'", "compgen -f", "'-config -force -mode -name'"},
+		CompletionShellZsh:  {"#compdef", "'consumer:Start consumer'", "'-mode[run mode]:mode:(sync async)'", "'-config[config file]:config:_files'", "'-force[don'\\''t wait]'"},
+		CompletionShellFish: {"-n '__fish_use_subcommand' -a consumer -d 'Start consumer'", "-o mode -d 'run mode' -r -a 'sync async'", "-o config -d 'config file' -r -F"},
+	}
+	for shell, expected := range cases {
+		buf := bytes.NewBuffer(nil)
+		if e := app.Completion(buf, shell); e != nil {
+			t.Fatal(e)
+		}
+		for _, s := range expected {
+			if !strings.Contains(buf.String(), s) {
+				t.Fatal(shell + " completion must contain " + s + "\n" + buf.String())
+			}
+		}
+		if strings.Contains(buf.String(), "secret") {
+			t.Fatal("hidden mode must be skipped")
+		}
+	}
+	if e := app.Completion(bytes.NewBuffer(nil), "powershell"); e == nil {
+		t.Fatal("must be unsupported shell error")
+	}
+	if e := app.RunFrom([]string{ModeCompletion, "tcsh"}); e == nil {
+		t.Fatal("completion mode must be built-in")
+	}
+}
@@ -0,0 +1,420 @@
+package gocli
+
+import (
+	"io/fs"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dimonrus/porterr"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigKeyDepends Config key with parent config or list of parent configs
+const ConfigKeyDepends = "depends"
+
+// regExpYamlLine line number and optional column in yaml or decoder error. Example: line 3:9: message
+var regExpYamlLine = regexp.MustCompile(`^line (\d+)(?::(\d+))?: (.*)$`)
+
+// regExpYamlValue quoted value in yaml error
+var regExpYamlValue = regexp.MustCompile("`[^`]*`")
+
+// parseConfig merge env config over parent configs and decode result into config values
+// Unknown keys in strict mode and validation rules violations are reported at once
+func (a *DNApp) parseConfig(env string, chain []string) porterr.IError {
+	invalid := porterr.New(porterr.PortErrorValidation, "Config "+env+" is invalid")
+	a.config.env = env
+	a.config.files = nil
+	a.config.secrets = nil
+	a.config.secretNodes = nil
+	defer func() {
+		a.config.secretNodes = nil
+	}()
+	nodes, e := a.loadConfig(env, chain, make(map[string]bool), invalid)
+	if e != nil {
+		return e
+	}
+	var node, masked *yaml.Node
+	for _, n := range nodes {
+		node = a.mergeConfig(node, n, "")
+		// masked copy is merged the same way to mask secrets in config dump
+		if len(a.config.secretNodes) > 0 {
+			masked = a.mergeConfig(masked, maskNode(n, a.config.secretNodes), "")
+		}
+	}
+	if len(a.config.secretNodes) == 0 {
+		masked = node
+	}
+	a.config.node, a.config.masked = node, masked
+	if node != nil {
+		// unmarshal merged config in config struct
+		err := node.Decode(a.config.values)
+		if err != nil {
+			return porterr.New(porterr.PortErrorDecoder, env+": "+err.Error())
+		}
+	}
+	return invalid.MergeDetails(ValidateConfig(a.config.values)).IfDetails()
+}
+
+// ParseConfigE Parse config and return error instead of fatal error
+// Config is parsed aside and set under lock to not race with WatchConfig
+func (a *DNApp) ParseConfigE(env string) porterr.IError {
+	a.configMutex.RLock()
+	next := a.configParser(a.config, a.config.values)
+	a.configMutex.RUnlock()
+	e := next.parseConfig(env, nil)
+	a.swapConfig(next)
+	return e
+}
+
+// loadConfig load env config and parent configs not loaded yet
+// Returns config nodes in merge order: parents in depends order before env, override after env file
+// Env shared by several parents is loaded and merged once. chain contains envs being loaded to detect depends cycles
+// Unknown keys are pushed to invalid error details in strict mode
+func (a *DNApp) loadConfig(env string, chain []string, loaded map[string]bool, invalid porterr.IError) ([]*yaml.Node, porterr.IError) {
+	for _, parent := range chain {
+		if parent == env {
+			return nil, porterr.New(porterr.PortErrorRecursion, "Config depends cycle: "+strings.Join(append(chain, env), " -> "))
+		}
+	}
+	if loaded[env] {
+		return nil, nil
+	}
+	loaded[env] = true
+	chain = append(chain[:len(chain):len(chain)], env)
+	path := a.GetConfigPath(env)
+	root, depends, e := a.readConfig(a.config.fsys, path, chain)
+	// embedded config may be missing if override exists
+	override, ok := a.getOverridePath(env)
+	if e != nil && !(ok && e.GetCode() == porterr.PortErrorIO) {
+		return nil, e
+	}
+	var overrideRoot *yaml.Node
+	if ok {
+		var overrideDepends []string
+		overrideRoot, overrideDepends, e = a.readConfig(nil, override, chain)
+		if e != nil {
+			return nil, e
+		}
+		if overrideDepends != nil {
+			depends = overrideDepends
+		}
+	}
+	var nodes []*yaml.Node
+	for _, parent := range depends {
+		parents, e := a.loadConfig(parent, chain, loaded, invalid)
+		if e != nil {
+			return nil, e
+		}
+		nodes = append(nodes, parents...)
+	}
+	// unknown keys of parents are reported first
+	if t := reflect.TypeOf(a.config.values); a.config.strict && t != nil && t.Kind() == reflect.Pointer {
+		if root != nil {
+			unknownKeys(root, t, "", path, invalid)
+		}
+		if overrideRoot != nil {
+			unknownKeys(overrideRoot, t, "", override, invalid)
+		}
+	}
+	for _, node := range []*yaml.Node{root, overrideRoot} {
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// readConfig read and decode config file from file system or from disk if fsys is nil
+// Returns root node without depends key and depends of file. Depends is nil if key is not defined
+func (a *DNApp) readConfig(fsys fs.FS, path string, chain []string) (*yaml.Node, []string, porterr.IError) {
+	var data []byte
+	var err error
+	if fsys == nil {
+		a.config.files = append(a.config.files, path)
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = fs.ReadFile(fsys, path)
+	}
+	if err != nil {
+		return nil, nil, porterr.New(porterr.PortErrorIO, err.Error()+dependsChain(chain))
+	}
+	root, err := a.getConfigDecoder(path).Decode(data)
+	if err != nil {
+		return nil, nil, a.configError(porterr.PortErrorDecoder, path, chain, err, nil, data)
+	}
+	e := a.expandConfig(root)
+	if e != nil {
+		return nil, nil, porterr.New(porterr.PortErrorValidation, path+": "+e.Error()+dependsChain(chain)).MergeDetails(e)
+	}
+	if e = a.decryptConfig(root, path, chain); e != nil {
+		return nil, nil, e
+	}
+	depends, e := configDepends(root)
+	if e != nil {
+		return nil, nil, porterr.New(porterr.PortErrorDecoder, path+": "+e.Error()+dependsChain(chain))
+	}
+	if root == nil {
+		return nil, depends, nil
+	}
+	if root.Kind == yaml.MappingNode && keyIndex(root, ConfigKeyDepends) >= 0 && depends == nil {
+		depends = []string{}
+	}
+	removeKey(root, ConfigKeyDepends)
+	// decode file separately to report type errors with file position
+	if t := reflect.TypeOf(a.config.values); t != nil && t.Kind() == reflect.Pointer {
+		err = cloneNode(root).Decode(reflect.New(t.Elem()).Interface())
+		if err != nil {
+			return nil, nil, a.configError(porterr.PortErrorDecoder, path, chain, err, root, data)
+		}
+	}
+	return root, depends, nil
+}
+
+// configError create error with config file position and depends chain
+// Each yaml error is added as detail named by position. Example: config/local.yaml:3:9
+// Column is taken from error, from value node on line or from first char on line if decoder reports line only
+// Secret values are masked in messages
+func (a *DNApp) configError(code interface{}, path string, chain []string, err error, root *yaml.Node, data []byte) porterr.IError {
+	messages := []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	if typeError, ok := err.(*yaml.TypeError); ok {
+		messages = typeError.Errors
+	}
+	var e porterr.IError
+	for _, message := range messages {
+		position := path
+		if m := regExpYamlLine.FindStringSubmatch(message); m != nil {
+			position += ":" + m[1]
+			line, _ := strconv.Atoi(m[1])
+			column, _ := strconv.Atoi(m[2])
+			if column == 0 {
+				column = nodeColumn(root, line)
+			}
+			if column == 0 {
+				column = lineColumn(data, line)
+			}
+			if column > 0 {
+				position += ":" + strconv.Itoa(column)
+			}
+			message = m[3]
+			// yaml truncates quoted values so secret may be not found in message
+			if a.secretOnLine(root, line) {
+				message = regExpYamlValue.ReplaceAllString(message, "`"+ConfigSecretMask+"`")
+			}
+		}
+		message = maskSecrets(message, a.config.secrets)
+		if e == nil {
+			e = porterr.New(code, position+": "+message+dependsChain(chain))
+		}
+		e = e.PushDetail(code, position, message)
+	}
+	return e
+}
+
+// nodeColumn column of first value node on line. Zero if not found
+func nodeColumn(node *yaml.Node, line int) int {
+	if node == nil {
+		return 0
+	}
+	if node.Line == line && node.Kind == yaml.ScalarNode {
+		return node.Column
+	}
+	for i, child := range node.Content {
+		// skip mapping keys to point on values
+		if node.Kind == yaml.MappingNode && i%2 == 0 && i+1 < len(node.Content) && node.Content[i+1].Line == line {
+			continue
+		}
+		if column := nodeColumn(child, line); column > 0 {
+			return column
+		}
+	}
+	if node.Line == line {
+		return node.Column
+	}
+	return 0
+}
+
+// lineColumn column of first not blank char on line. Zero if line is not found
+func lineColumn(data []byte, line int) int {
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+	text := lines[line-1]
+	return len(text) - len(strings.TrimLeft(text, " \t")) + 1
+}
+
+// dependsChain render depends chain for error messages
+func dependsChain(chain []string) string {
+	if len(chain) < 2 {
+		return ""
+	}
+	return " (depends: " + strings.Join(chain, " -> ") + ")"
+}
+
+// expandConfig replace variables in scalar values of config with replaceEnv
+// Mapping keys and comments are not changed. Plain scalar is typed after substitution. Example: port: ${PORT} is int
+func (a *DNApp) expandConfig(root *yaml.Node) porterr.IError {
+	e := porterr.New(porterr.PortErrorArgument, "Environment variables or secret files are not defined")
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			value, masked, ve := a.expandValue(node.Value)
+			if ve != nil {
+				e = e.MergeDetails(ve)
+				return
+			}
+			if value == node.Value {
+				return
+			}
+			if masked != value {
+				a.secretNode(node, masked)
+			}
+			node.Value = value
+			if node.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = ""
+				node.Tag = node.ShortTag()
+			}
+		case yaml.MappingNode:
+			for i := 1; i < len(node.Content); i += 2 {
+				walk(node.Content[i])
+			}
+		default:
+			for _, child := range node.Content {
+				walk(child)
+			}
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	return e.IfDetails()
+}
+
+// replaceEnv replace ${VAR} with environment variables
+// Undefined ${VAR} is replaced with empty string
+// ${VAR:-default} uses default when VAR is empty or not defined
+// ${VAR:?message} returns error when VAR is empty or not defined
+// ${file:/path} is replaced with trimmed content of secret file
+// $$ is replaced with $
+func (a *DNApp) replaceEnv(content string) (string, porterr.IError) {
+	value, _, e := a.expandValue(content)
+	return value, e
+}
+
+// expandValue replace variables in value with replaceEnv rules
+// Masked value contains ConfigSecretMask in place of secret files content
+func (a *DNApp) expandValue(content string) (string, string, porterr.IError) {
+	e := porterr.New(porterr.PortErrorArgument, "Environment variables or secret files are not defined")
+	var masks []string
+	var secret bool
+	value := regExpExpand.ReplaceAllStringFunc(content, func(m string) string {
+		var result string
+		var isSecret bool
+		result, isSecret, e = a.expandVariable(m, e)
+		mask := result
+		if isSecret {
+			secret, mask = true, ConfigSecretMask
+		}
+		masks = append(masks, mask)
+		return result
+	})
+	masked := value
+	if secret {
+		var i int
+		masked = regExpExpand.ReplaceAllStringFunc(content, func(string) string {
+			i++
+			return masks[i-1]
+		})
+	}
+	return value, masked, e.IfDetails()
+}
+
+// expandVariable get replacement of ${...} or $$ match and true if it is secret. Errors are pushed to e details
+func (a *DNApp) expandVariable(m string, e porterr.IError) (string, bool, porterr.IError) {
+	if m == "$$" {
+		return "$", false, e
+	}
+	expression := m[2 : len(m)-1]
+	if strings.HasPrefix(expression, ConfigSecretFilePrefix) {
+		path := strings.TrimPrefix(expression, ConfigSecretFilePrefix)
+		a.config.files = append(a.config.files, path)
+		value, err := readSecret(path)
+		if err != nil {
+			return m, false, e.PushDetail(porterr.PortErrorIO, path, err.Error())
+		}
+		a.registerSecret(value)
+		return value, true, e
+	}
+	name, operator, operand := expression, "", ""
+	if i := strings.Index(expression, ":"); i >= 0 {
+		name, operator = expression[:i], expression[i:]
+		if len(operator) > 2 {
+			operator, operand = operator[:2], operator[2:]
+		}
+	}
+	if name == "" {
+		return m, false, e.PushDetail(porterr.PortErrorArgument, m, "variable name is empty")
+	}
+	v, ok := os.LookupEnv(name)
+	switch operator {
+	case "":
+	case ":-":
+		if v == "" {
+			return operand, false, e
+		}
+		return v, false, e
+	case ":?":
+		if v == "" {
+			if operand == "" {
+				operand = "is not defined"
+			}
+			e = e.PushDetail(porterr.PortErrorArgument, name, operand)
+		}
+		return v, false, e
+	default:
+		return m, false, e.PushDetail(porterr.PortErrorArgument, name, "unknown operator "+operator+" in "+m)
+	}
+	if !ok {
+		a.FailMessage("Environment: " + name + " is not defined")
+	}
+	return v, false, e
+}
+
+// configDepends get parent configs from top level depends key
+// depends may be empty, a single name or a list of names
+func configDepends(root *yaml.Node) ([]string, porterr.IError) {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != ConfigKeyDepends {
+			continue
+		}
+		value := root.Content[i+1]
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if value.Tag == "!!null" || strings.TrimSpace(value.Value) == "" {
+				return nil, nil
+			}
+			return []string{strings.TrimSpace(value.Value)}, nil
+		case yaml.SequenceNode:
+			var depends []string
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, porterr.NewF(porterr.PortErrorDecoder, "line %d: %s item must be a config name", item.Line, ConfigKeyDepends)
+				}
+				if name := strings.TrimSpace(item.Value); name != "" {
+					depends = append(depends, name)
+				}
+			}
+			return depends, nil
+		default:
+			return nil, porterr.NewF(porterr.PortErrorDecoder, "line %d: %s must be a config name or list of names", value.Line, ConfigKeyDepends)
+		}
+	}
+	return nil, nil
+}
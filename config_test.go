@@ -0,0 +1,238 @@
+package gocli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testConfig struct {
+	Project struct {
+		Name  string
+		Debug bool
+	}
+	Web struct {
+		Port int
+		Host string
+	}
+	Features []string
+	Note     string
+}
+
+// writeTestConfigs write config files into temp dir
+func writeTestConfigs(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDNApp_parseConfig(t *testing.T) {
+	t.Run("depends_list", func(t *testing.T) {
+		dir := writeTestConfigs(t, map[string]string{
+			"global.yaml":  "depends:\nproject:\n  name: dna\nweb:\n  port: 8080\n  host: 0.0.0.0\n",
+			"secrets.yaml": "depends: global\nweb:\n  port: 9000\n",
+			"feature.yaml": "# depends: local\nfeatures: [a, b]\nnote: 'depends: local'\n",
+			"local.yaml":   "depends: [secrets, feature]\nproject:\n  debug: true\n",
+		})
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: dir}}
+		if e := app.parseConfig("local", nil); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Project.Name != "dna" || !cfg.Project.Debug || cfg.Web.Port != 9000 || cfg.Web.Host != "0.0.0.0" {
+			t.Fatal("wrong depends order", cfg)
+		}
+		if len(cfg.Features) != 2 || cfg.Note != "depends: local" {
+			t.Fatal("depends in values and comments must be ignored", cfg)
+		}
+	})
+	t.Run("diamond", func(t *testing.T) {
+		dir := writeTestConfigs(t, map[string]string{
+			"global.yaml":  "web:\n  port: 1\n  host: 0.0.0.0\n",
+			"secrets.yaml": "depends: global\nweb:\n  port: 2\n",
+			"feature.yaml": "depends: global\nfeatures: [a]\n",
+			"local.yaml":   "depends: [secrets, feature]\nproject:\n  debug: true\n",
+		})
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: dir}}
+		if e := app.parseConfig("local", nil); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Web.Port != 2 || cfg.Web.Host != "0.0.0.0" || len(cfg.Features) != 1 {
+			t.Fatal("shared parent must be merged once", cfg)
+		}
+		if len(app.config.files) != 4 {
+			t.Fatal("shared parent must be read once", app.config.files)
+		}
+	})
+	t.Run("cycle", func(t *testing.T) {
+		dir := writeTestConfigs(t, map[string]string{
+			"a.yaml": "depends: b\n",
+			"b.yaml": "depends:\n  - c\n",
+			"c.yaml": "depends: [a]\n",
+		})
+		app := &DNApp{config: config{values: &testConfig{}, path: dir}}
+		e := app.parseConfig("a", nil)
+		if e == nil || !strings.Contains(e.Error(), "a -> b -> c -> a") {
+			t.Fatal("must be cycle error", e)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		dir := writeTestConfigs(t, map[string]string{
+			"a.yaml": "depends:\n  name: b\n",
+		})
+		app := &DNApp{config: config{values: &testConfig{}, path: dir}}
+		if e := app.parseConfig("a", nil); e == nil {
+			t.Fatal("depends must be name or list")
+		}
+		if e := app.parseConfig("missing", nil); e == nil {
+			t.Fatal("missing config must be error")
+		}
+	})
+}
+
+func TestDNApp_replaceEnv(t *testing.T) {
+	t.Setenv("GOCLI_TEST_HOST", "localhost")
+	t.Setenv("GOCLI_TEST_PORT", "8080")
+	t.Setenv("GOCLI_TEST_EMPTY", "")
+	app := &DNApp{logger: NewLogger(LoggerConfig{})}
+	t.Run("multiple", func(t *testing.T) {
+		content, e := app.replaceEnv("url: ${GOCLI_TEST_HOST}:${GOCLI_TEST_PORT}")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if content != "url: localhost:8080" {
+			t.Fatal("wrong content", content)
+		}
+	})
+	t.Run("default", func(t *testing.T) {
+		content, e := app.replaceEnv("${GOCLI_TEST_MISSING:-0.0.0.0}:${GOCLI_TEST_EMPTY:-80}:${GOCLI_TEST_PORT:-80}")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if content != "0.0.0.0:80:8080" {
+			t.Fatal("wrong content", content)
+		}
+	})
+	t.Run("escape", func(t *testing.T) {
+		content, e := app.replaceEnv("price: $$5 ${GOCLI_TEST_PORT} $${GOCLI_TEST_PORT}")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if content != "price: $5 8080 ${GOCLI_TEST_PORT}" {
+			t.Fatal("wrong content", content)
+		}
+	})
+	t.Run("required", func(t *testing.T) {
+		_, e := app.replaceEnv("${GOCLI_TEST_MISSING:?token is required} ${GOCLI_TEST_EMPTY:?} ${GOCLI_TEST_HOST:?}")
+		if e == nil || len(e.GetDetails()) != 2 {
+			t.Fatal("must be two required errors", e)
+		}
+		if e.GetDetails()[0].Origin().Message != "token is required" {
+			t.Fatal("wrong message", e.GetDetails()[0])
+		}
+	})
+	t.Run("undefined", func(t *testing.T) {
+		content, e := app.replaceEnv("host: ${GOCLI_TEST_MISSING}")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if content != "host: " {
+			t.Fatal("undefined variable must be empty", content)
+		}
+	})
+	t.Run("operator", func(t *testing.T) {
+		_, e := app.replaceEnv("${GOCLI_TEST_HOST:x} ${GOCLI_TEST_HOST:} ${:-80}")
+		if e == nil || len(e.GetDetails()) != 3 {
+			t.Fatal("must be operator errors", e)
+		}
+	})
+	t.Run("comment", func(t *testing.T) {
+		dir := writeTestConfigs(t, map[string]string{
+			"a.yaml": "# token: ${GOCLI_TEST_MISSING:?} ${file:/missing}\nweb:\n  port: ${GOCLI_TEST_PORT} # ${GOCLI_TEST_MISSING:?}\n  host: ${GOCLI_TEST_HOST}\nnote: '${GOCLI_TEST_PORT}'\n",
+		})
+		var cfg testConfig
+		app.config = config{values: &cfg, path: dir}
+		if e := app.parseConfig("a", nil); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Web.Port != 8080 || cfg.Web.Host != "localhost" || cfg.Note != "8080" {
+			t.Fatal("wrong substitution", cfg)
+		}
+	})
+	t.Run("parse_config", func(t *testing.T) {
+		dir := writeTestConfigs(t, map[string]string{
+			"a.yaml": "web:\n  port: ${GOCLI_TEST_MISSING:?port is required}\n",
+		})
+		app.config = config{values: &testConfig{}, path: dir}
+		e := app.parseConfig("a", nil)
+		if e == nil || !strings.Contains(e.Error(), "a.yaml") || len(e.GetDetails()) != 1 {
+			t.Fatal("must be required error", e)
+		}
+	})
+}
+
+func TestDNApp_ParseConfigE(t *testing.T) {
+	dir := writeTestConfigs(t, map[string]string{
+		"global.yaml": "web:\n  port: 8080\n",
+		"type.yaml":   "depends: global\nweb:\n  host: localhost\n  port: abc\n",
+		"syntax.yaml": "web:\n  port: 80\n host: localhost\n",
+		"column.json": "{\"web\": {\"port\": 80, \"host\" \"x\"}}",
+		"local.yaml":  "depends: type\n",
+		"parent.yaml": "depends: missing\n",
+	})
+	t.Run("type", func(t *testing.T) {
+		_, e := NewApplicationE("local", dir, &testConfig{})
+		if e == nil {
+			t.Fatal("must be type error")
+		}
+		if !strings.Contains(e.Error(), "type.yaml:4:9: ") || !strings.Contains(e.Error(), "(depends: local -> type)") {
+			t.Fatal("wrong error", e)
+		}
+		if len(e.GetDetails()) != 1 || !strings.HasSuffix(e.GetDetails()[0].Origin().Name, "type.yaml:4:9") {
+			t.Fatal("wrong details", e.GetDetails())
+		}
+	})
+	t.Run("syntax", func(t *testing.T) {
+		_, e := NewApplicationE("syntax", dir, &testConfig{})
+		if e == nil || !strings.Contains(e.Error(), "syntax.yaml:2:3: ") {
+			t.Fatal("must be syntax error", e)
+		}
+	})
+	t.Run("column", func(t *testing.T) {
+		_, e := NewApplicationE("column", dir, &testConfig{})
+		if e == nil || !strings.Contains(e.Error(), "column.json:1:29: ") {
+			t.Fatal("must be syntax error with column", e)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, e := NewApplicationE("parent", dir, &testConfig{})
+		if e == nil || !strings.Contains(e.Error(), "(depends: parent -> missing)") {
+			t.Fatal("must be io error", e)
+		}
+	})
+	t.Run("fatal_handler", func(t *testing.T) {
+		var fatal error
+		app := &DNApp{config: config{values: &testConfig{}, path: dir}}
+		app.SetFatalHandler(func(app Application, err error) {
+			fatal = err
+		})
+		app.ParseConfig("syntax")
+		if fatal == nil {
+			t.Fatal("fatal handler must be called")
+		}
+	})
+	t.Run("fatal_panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("must panic by default")
+			}
+		}()
+		NewApplication("syntax", dir, &testConfig{})
+	})
+}
@@ -0,0 +1,270 @@
+package gocli
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dimonrus/porterr"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigTagEncrypted Tag of encrypted config value. Example: password: !encrypted c2VjcmV0...
+	ConfigTagEncrypted = "!encrypted"
+	// ConfigEncryptionKeyEnv Default environment variable with base64 encoded encryption key
+	ConfigEncryptionKeyEnv = "GOCLI_CONFIG_KEY"
+	// ConfigEncryptionKeySize Size of generated encryption key in bytes. AES-256
+	ConfigEncryptionKeySize = 32
+
+	// ModeConfig Built-in hidden mode for config values encryption
+	ModeConfig = "config"
+	// ConfigActionEncrypt Encrypt value with encryption key
+	ConfigActionEncrypt = "encrypt"
+	// ConfigActionDecrypt Decrypt value with encryption key
+	ConfigActionDecrypt = "decrypt"
+	// ConfigActionKey Generate encryption key
+	ConfigActionKey = "key"
+)
+
+// GenerateEncryptionKey Generate base64 encoded random encryption key
+func GenerateEncryptionKey() (string, porterr.IError) {
+	key := make([]byte, ConfigEncryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", porterr.New(porterr.PortErrorIO, err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptValue Encrypt value with AES-GCM. Result is base64 encoded nonce and cipher text
+func EncryptValue(key []byte, value string) (string, porterr.IError) {
+	gcm, e := newGCM(key)
+	if e != nil {
+		return "", e
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", porterr.New(porterr.PortErrorIO, err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+// DecryptValue Decrypt value encrypted with EncryptValue
+func DecryptValue(key []byte, value string) (string, porterr.IError) {
+	gcm, e := newGCM(key)
+	if e != nil {
+		return "", e
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return "", porterr.New(porterr.PortErrorDecoder, "encrypted value is not base64: "+err.Error())
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", porterr.New(porterr.PortErrorDecoder, "encrypted value is too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", porterr.New(porterr.PortErrorDecoder, "encrypted value can not be decrypted: "+err.Error())
+	}
+	return string(plain), nil
+}
+
+// newGCM create AES-GCM cipher with 16, 24 or 32 bytes key
+func newGCM(key []byte) (cipher.AEAD, porterr.IError) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, porterr.New(porterr.PortErrorArgument, "encryption key is invalid: "+err.Error())
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, porterr.New(porterr.PortErrorArgument, err.Error())
+	}
+	return gcm, nil
+}
+
+// GetEncryptionKeyEnv Get environment variable with encryption key
+func (a *DNApp) GetEncryptionKeyEnv() string {
+	if a.config.keyEnv == "" {
+		return ConfigEncryptionKeyEnv
+	}
+	return a.config.keyEnv
+}
+
+// SetEncryptionKeyEnv Set environment variable with base64 encoded encryption key
+// Key is used on next ParseConfig or reload. Use WithEncryptionKeyEnv to decrypt config on first parse
+func (a *DNApp) SetEncryptionKeyEnv(name string) {
+	a.config.keyEnv = name
+}
+
+// SetEncryptionKeyFile Set file with base64 encoded encryption key. Key file is used before environment variable
+// Key is used on next ParseConfig or reload. Use WithEncryptionKeyFile to decrypt config on first parse
+func (a *DNApp) SetEncryptionKeyFile(path string) {
+	a.config.keyFile = path
+}
+
+// WithEncryptionKeyEnv Set environment variable with encryption key before config is parsed
+// Example: gocli.NewApplication(env, path, &config, gocli.WithEncryptionKeyEnv("MYSERVICE_CONFIG_KEY"))
+func WithEncryptionKeyEnv(name string) ApplicationOption {
+	return func(app *DNApp) {
+		app.SetEncryptionKeyEnv(name)
+	}
+}
+
+// WithEncryptionKeyFile Set file with encryption key before config is parsed
+// Example: gocli.NewApplication(env, path, &config, gocli.WithEncryptionKeyFile("/run/secrets/config_key"))
+func WithEncryptionKeyFile(path string) ApplicationOption {
+	return func(app *DNApp) {
+		app.SetEncryptionKeyFile(path)
+	}
+}
+
+// getEncryptionKey read encryption key from key file or environment variable
+func (a *DNApp) getEncryptionKey() ([]byte, porterr.IError) {
+	var encoded string
+	if a.config.keyFile != "" {
+		data, err := os.ReadFile(a.config.keyFile)
+		if err != nil {
+			return nil, porterr.New(porterr.PortErrorIO, "encryption key file: "+err.Error())
+		}
+		encoded = string(data)
+	} else {
+		encoded = os.Getenv(a.GetEncryptionKeyEnv())
+		if encoded == "" {
+			return nil, porterr.New(porterr.PortErrorArgument, "encryption key is not defined in "+a.GetEncryptionKeyEnv())
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, porterr.New(porterr.PortErrorArgument, "encryption key is not base64: "+err.Error())
+	}
+	return key, nil
+}
+
+// decryptConfig decrypt scalar nodes with encrypted tag. Decrypted values are masked as secrets
+func (a *DNApp) decryptConfig(root *yaml.Node, path string, chain []string) porterr.IError {
+	var key []byte
+	e := porterr.New(porterr.PortErrorDecoder, path+": encrypted values can not be decrypted"+dependsChain(chain))
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Tag == ConfigTagEncrypted {
+			position := path + ":" + strconv.Itoa(node.Line) + ":" + strconv.Itoa(node.Column)
+			if node.Kind != yaml.ScalarNode {
+				e = e.PushDetail(porterr.PortErrorDecoder, position, "encrypted value must be a scalar")
+				return
+			}
+			if key == nil {
+				var ke porterr.IError
+				key, ke = a.getEncryptionKey()
+				if ke != nil {
+					e = e.PushDetail(porterr.PortErrorArgument, position, ke.Error())
+					return
+				}
+			}
+			value, de := DecryptValue(key, node.Value)
+			if de != nil {
+				e = e.PushDetail(porterr.PortErrorDecoder, position, de.Error())
+				return
+			}
+			a.registerSecret(value)
+			a.secretNode(node, ConfigSecretMask)
+			node.Value, node.Tag, node.Style = value, "!!str", 0
+			return
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	return e.IfDetails()
+}
+
+// configMode built-in mode to encrypt and decrypt config values
+// Flags may be passed before or after action word. Example: config encrypt --key-file=/run/secrets/key value
+func (a *DNApp) configMode() Mode {
+	group := UsageGroup{
+		Name:        ModeConfig,
+		Description: "Encrypt config values. Usage: config <encrypt|decrypt|key> [flags] [value]. Value is read from stdin if omitted",
+		Arguments: ArgumentMap{
+			"key-file": {Type: ArgumentTypePath, Label: "file with base64 encoded encryption key"},
+		},
+	}
+	return Mode{
+		Name:        group.Name,
+		Description: group.Description,
+		Hidden:      true,
+		Arguments:   group.Arguments,
+		Runner: func(args ...Argument) porterr.IError {
+			keyFile := Arguments(args).GetByName("key-file").StringOr("")
+			var action string
+			var rest []string
+			if a.flags != nil && a.flags.NArg() > 0 {
+				action = a.flags.Arg(0)
+				// parse flags following action word
+				if e := a.parseFlags(group, a.flags.Args()[1:]); e != nil {
+					return e
+				}
+				if value := group.Arguments["key-file"].StringOr(""); value != "" {
+					keyFile = value
+				}
+				rest = a.flags.Args()
+			}
+			if keyFile != "" {
+				a.SetEncryptionKeyFile(keyFile)
+			}
+			if len(rest) > 1 {
+				return porterr.New(porterr.PortErrorArgument, "unexpected config arguments: "+strings.Join(rest[1:], " ")+". Flags must precede value")
+			}
+			var value string
+			if len(rest) == 1 {
+				value = rest[0]
+			} else if action == ConfigActionEncrypt || action == ConfigActionDecrypt {
+				// read value from stdin to keep it out of shell history
+				line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && err != io.EOF {
+					return porterr.New(porterr.PortErrorIO, err.Error())
+				}
+				value = strings.TrimRight(line, "\r\n")
+			}
+			return a.ConfigCrypt(os.Stdout, action, value)
+		},
+	}
+}
+
+// ConfigCrypt Write encrypted or decrypted value or generated encryption key
+func (a *DNApp) ConfigCrypt(w io.Writer, action string, value string) porterr.IError {
+	var result string
+	var e porterr.IError
+	switch action {
+	case ConfigActionKey:
+		result, e = GenerateEncryptionKey()
+	case ConfigActionEncrypt, ConfigActionDecrypt:
+		var key []byte
+		key, e = a.getEncryptionKey()
+		if e != nil {
+			return e
+		}
+		if action == ConfigActionEncrypt {
+			result, e = EncryptValue(key, value)
+			result = ConfigTagEncrypted + " " + result
+		} else {
+			result, e = DecryptValue(key, strings.TrimPrefix(strings.TrimSpace(value), ConfigTagEncrypted))
+		}
+	default:
+		return porterr.New(porterr.PortErrorArgument, "config action must be one of: "+ConfigActionEncrypt+", "+ConfigActionDecrypt+", "+ConfigActionKey)
+	}
+	if e != nil {
+		return e
+	}
+	if _, err := io.WriteString(w, result+"\n"); err != nil {
+		return porterr.New(porterr.PortErrorIO, err.Error())
+	}
+	return nil
+}
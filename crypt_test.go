@@ -0,0 +1,164 @@
+package gocli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dimonrus/porterr"
+)
+
+func TestEncryptValue(t *testing.T) {
+	encoded, e := GenerateEncryptionKey()
+	if e != nil {
+		t.Fatal(e)
+	}
+	key, _ := base64.StdEncoding.DecodeString(encoded)
+	if len(key) != ConfigEncryptionKeySize {
+		t.Fatal("wrong key size")
+	}
+	encrypted, e := EncryptValue(key, "p@ssword")
+	if e != nil {
+		t.Fatal(e)
+	}
+	other, _ := EncryptValue(key, "p@ssword")
+	if encrypted == other || strings.Contains(encrypted, "p@ssword") {
+		t.Fatal("encrypted value must be random")
+	}
+	value, e := DecryptValue(key, encrypted)
+	if e != nil || value != "p@ssword" {
+		t.Fatal("wrong decrypted value", value, e)
+	}
+	key[0]++
+	if _, e = DecryptValue(key, encrypted); e == nil {
+		t.Fatal("wrong key must be error")
+	}
+	if _, e = DecryptValue(key[:5], encrypted); e == nil {
+		t.Fatal("invalid key must be error")
+	}
+}
+
+func TestDNApp_decryptConfig(t *testing.T) {
+	encoded, _ := GenerateEncryptionKey()
+	key, _ := base64.StdEncoding.DecodeString(encoded)
+	encrypted, _ := EncryptValue(key, "p@ssword")
+	dir := writeTestConfigs(t, map[string]string{
+		"global.yaml": "project:\n  name: !encrypted " + encrypted + "\nnote: plain\n",
+		"local.yaml":  "depends: global\nnote: !encrypted " + encrypted + "\n",
+		"broken.yaml": "note: !encrypted bm90IGVuY3J5cHRlZA==\nproject: !encrypted\n  name: dna\n",
+		"key":         encoded + "\n",
+	})
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("GOCLI_TEST_KEY", encoded)
+		var cfg testConfig
+		app, e := NewApplicationE("local", dir, &cfg, WithEncryptionKeyEnv("GOCLI_TEST_KEY"))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Project.Name != "p@ssword" || cfg.Note != "p@ssword" {
+			t.Fatal("wrong decrypted config", cfg)
+		}
+		data, _ := app.EffectiveConfig()
+		if strings.Contains(string(data), "p@ssword") || strings.Contains(string(data), ConfigTagEncrypted) {
+			t.Fatal("decrypted value must be masked", string(data))
+		}
+	})
+	t.Run("key_file", func(t *testing.T) {
+		var cfg testConfig
+		_, e := NewApplicationE("local", dir, &cfg, WithEncryptionKeyFile(filepath.Join(dir, "key")))
+		if e != nil || cfg.Note != "p@ssword" {
+			t.Fatal("wrong decrypted config", e)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		app := &DNApp{config: config{values: &testConfig{}, path: dir}}
+		app.SetEncryptionKeyEnv("GOCLI_TEST_MISSING_KEY")
+		e := app.ParseConfigE("local")
+		if e == nil || !strings.Contains(e.GetDetails()[0].Error(), "GOCLI_TEST_MISSING_KEY") {
+			t.Fatal("must be missing key error", e)
+		}
+		app.SetEncryptionKeyFile(filepath.Join(dir, "key"))
+		e = app.ParseConfigE("broken")
+		if e == nil || len(e.GetDetails()) != 2 || !strings.HasSuffix(e.GetDetails()[0].Origin().Name, "broken.yaml:1:7") {
+			t.Fatal("must be decrypt errors", e)
+		}
+	})
+}
+
+func TestDNApp_ConfigCrypt(t *testing.T) {
+	app := &DNApp{}
+	var buf bytes.Buffer
+	if e := app.ConfigCrypt(&buf, ConfigActionKey, ""); e != nil {
+		t.Fatal(e)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	app.SetEncryptionKeyFile(keyFile)
+	buf.Reset()
+	if e := app.ConfigCrypt(&buf, ConfigActionEncrypt, "p@ssword"); e != nil {
+		t.Fatal(e)
+	}
+	if !strings.HasPrefix(buf.String(), ConfigTagEncrypted+" ") {
+		t.Fatal("encrypted value must be tagged", buf.String())
+	}
+	encrypted := buf.String()
+	buf.Reset()
+	if e := app.ConfigCrypt(&buf, ConfigActionDecrypt, encrypted); e != nil || buf.String() != "p@ssword\n" {
+		t.Fatal("wrong decrypted value", buf.String(), e)
+	}
+	if e := app.ConfigCrypt(&buf, "rotate", ""); e == nil {
+		t.Fatal("unknown action must be error")
+	}
+	app.SetOutput(&bytes.Buffer{})
+	if e := app.RunFrom([]string{ModeConfig, "rotate"}); e == nil {
+		t.Fatal("config mode must be built-in")
+	}
+}
+
+func TestDNApp_configMode(t *testing.T) {
+	key, e := GenerateEncryptionKey()
+	if e != nil {
+		t.Fatal(e)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// run config mode in new application and get stdout
+	run := func(args ...string) (string, porterr.IError) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		app := &DNApp{}
+		app.SetOutput(&bytes.Buffer{})
+		e := app.RunFrom(append([]string{ModeConfig}, args...))
+		os.Stdout = stdout
+		_ = w.Close()
+		data, _ := io.ReadAll(r)
+		return string(data), e
+	}
+	encrypted, e := run(ConfigActionEncrypt, "--key-file="+keyFile, "p@ssword")
+	if e != nil || !strings.HasPrefix(encrypted, ConfigTagEncrypted+" ") {
+		t.Fatal("wrong encrypted value", encrypted, e)
+	}
+	decrypted, e := run("--key-file", keyFile, ConfigActionDecrypt, strings.TrimSpace(encrypted))
+	if e != nil || decrypted != "p@ssword\n" {
+		t.Fatal("wrong decrypted value", decrypted, e)
+	}
+	decrypted, e = run(ConfigActionDecrypt, "-key-file", keyFile, strings.TrimSpace(encrypted))
+	if e != nil || decrypted != "p@ssword\n" {
+		t.Fatal("wrong decrypted value", decrypted, e)
+	}
+	if _, e = run(ConfigActionEncrypt, "p@ssword", "--key-file="+keyFile); e == nil {
+		t.Fatal("flag after value must be error")
+	}
+}
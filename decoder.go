@@ -0,0 +1,250 @@
+package gocli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigExtensionYaml Yaml config file extension. Default config format
+	ConfigExtensionYaml = ".yaml"
+	// ConfigExtensionYml Short yaml config file extension
+	ConfigExtensionYml = ".yml"
+	// ConfigExtensionJson Json config file extension
+	ConfigExtensionJson = ".json"
+	// ConfigExtensionEnv Dotenv config file extension. Example: WEB__PORT=8080
+	ConfigExtensionEnv = ".env"
+	// ConfigExtensionToml Toml config file extension
+	ConfigExtensionToml = ".toml"
+
+	// EnvKeyDelimiter Delimiter of nested keys in dotenv config
+	EnvKeyDelimiter = "__"
+)
+
+// ConfigDecoder Decoder of config file format into yaml node
+// Nodes of all formats are merged and decoded the same way. Nil node means empty config
+type ConfigDecoder interface {
+	Decode(data []byte) (*yaml.Node, error)
+}
+
+// ConfigDecoderFunc Function implementing ConfigDecoder
+type ConfigDecoderFunc func(data []byte) (*yaml.Node, error)
+
+// Decode Call decoder function
+func (f ConfigDecoderFunc) Decode(data []byte) (*yaml.Node, error) {
+	return f(data)
+}
+
+// defaultConfigExtensions Config extensions in lookup order
+var defaultConfigExtensions = []string{ConfigExtensionYaml, ConfigExtensionYml, ConfigExtensionJson, ConfigExtensionEnv, ConfigExtensionToml}
+
+// defaultConfigDecoders Built-in config decoders by extension
+var defaultConfigDecoders = map[string]ConfigDecoder{
+	ConfigExtensionYaml: ConfigDecoderFunc(DecodeYamlConfig),
+	ConfigExtensionYml:  ConfigDecoderFunc(DecodeYamlConfig),
+	ConfigExtensionJson: ConfigDecoderFunc(DecodeJsonConfig),
+	ConfigExtensionEnv:  ConfigDecoderFunc(DecodeEnvConfig),
+	ConfigExtensionToml: ConfigDecoderFunc(DecodeTomlConfig),
+}
+
+// SetConfigDecoder Register decoder for config file extension. Example: app.SetConfigDecoder(".ini", decoder)
+// Registered extensions are looked up after built-in extensions
+// Decoder is used on next ParseConfig or reload. Use WithConfigDecoder to decode config on first parse
+func (a *DNApp) SetConfigDecoder(extension string, decoder ConfigDecoder) {
+	if a.configDecoders == nil {
+		a.configDecoders = make(map[string]ConfigDecoder)
+	}
+	if _, ok := a.configDecoders[extension]; !ok {
+		a.configExtensions = append(a.configExtensions, extension)
+	}
+	a.configDecoders[extension] = decoder
+}
+
+// WithConfigDecoder Register decoder for config file extension before config is parsed
+// Example: gocli.NewApplication(env, path, &config, gocli.WithConfigDecoder(".ini", decoder))
+func WithConfigDecoder(extension string, decoder ConfigDecoder) ApplicationOption {
+	return func(app *DNApp) {
+		app.SetConfigDecoder(extension, decoder)
+	}
+}
+
+// getConfigExtensions built-in and registered config extensions in lookup order
+func (a *DNApp) getConfigExtensions() []string {
+	extensions := append([]string{}, defaultConfigExtensions...)
+	for _, extension := range a.configExtensions {
+		if _, ok := defaultConfigDecoders[extension]; !ok {
+			extensions = append(extensions, extension)
+		}
+	}
+	return extensions
+}
+
+// getConfigDecoder decoder for config file by extension
+func (a *DNApp) getConfigDecoder(path string) ConfigDecoder {
+	extension := filepath.Ext(path)
+	if decoder, ok := a.configDecoders[extension]; ok {
+		return decoder
+	}
+	if decoder, ok := defaultConfigDecoders[extension]; ok {
+		return decoder
+	}
+	return defaultConfigDecoders[ConfigExtensionYaml]
+}
+
+// DecodeYamlConfig Decode yaml config
+func DecodeYamlConfig(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil || len(doc.Content) == 0 {
+		return nil, err
+	}
+	return doc.Content[0], nil
+}
+
+// regExpPlaceholder value consisting of single variable. Example: ${PORT}
+var regExpPlaceholder = regexp.MustCompile(`^\$\{[^{}]*\}$`)
+
+// DecodeJsonConfig Decode json config. Json is parsed as yaml after syntax check
+// String value consisting of single variable is typed after substitution. Example: "port": "${PORT}" is int
+func DecodeJsonConfig(data []byte) (*yaml.Node, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		var syntaxError *json.SyntaxError
+		if errors.As(err, &syntaxError) {
+			// offset is counted after erroneous char
+			offset := int(syntaxError.Offset) - 1
+			if offset < 0 {
+				offset = 0
+			}
+			line := bytes.Count(data[:offset], []byte("\n")) + 1
+			column := offset - bytes.LastIndexByte(data[:offset], '\n')
+			return nil, errors.New("line " + strconv.Itoa(line) + ":" + strconv.Itoa(column) + ": " + err.Error())
+		}
+		return nil, err
+	}
+	root, err := DecodeYamlConfig(data)
+	if root != nil {
+		plainPlaceholders(root)
+	}
+	return root, err
+}
+
+// plainPlaceholders make quoted values consisting of single variable plain to type them after substitution
+func plainPlaceholders(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style == yaml.DoubleQuotedStyle && regExpPlaceholder.MatchString(node.Value) {
+			node.Style = 0
+		}
+	case yaml.MappingNode:
+		// keys are not substituted
+		for i := 1; i < len(node.Content); i += 2 {
+			plainPlaceholders(node.Content[i])
+		}
+	default:
+		for _, child := range node.Content {
+			plainPlaceholders(child)
+		}
+	}
+}
+
+// DecodeEnvConfig Decode dotenv config with KEY=VALUE lines
+// Nested keys are separated with EnvKeyDelimiter or dot and lower cased. Example: WEB__PORT=8080 is web.port
+// Unquoted values are typed as yaml values. Depends may be a comma separated list
+func DecodeEnvConfig(data []byte) (*yaml.Node, error) {
+	var root *yaml.Node
+	for i, line := range strings.Split(string(data), "\n") {
+		number := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.New("line " + strconv.Itoa(number) + ":1: expected KEY=VALUE")
+		}
+		column := strings.Index(line, "=") + 2
+		value, err := envValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, errors.New("line " + strconv.Itoa(number) + ":" + strconv.Itoa(column) + ": " + err.Error())
+		}
+		value.Line, value.Column = number, column
+		path := strings.Split(strings.ToLower(strings.ReplaceAll(key, EnvKeyDelimiter, ".")), ".")
+		if len(path) == 1 && path[0] == ConfigKeyDepends && value.Style == 0 && strings.Contains(value.Value, ",") {
+			value.Kind, value.Content = yaml.SequenceNode, nil
+			for _, name := range strings.Split(value.Value, ",") {
+				value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: strings.TrimSpace(name), Line: number})
+			}
+			value.Value = ""
+		}
+		if root == nil {
+			root = &yaml.Node{Kind: yaml.MappingNode, Line: number, Column: 1}
+		}
+		node := root
+		for j, name := range path {
+			k := keyIndex(node, name)
+			if j == len(path)-1 {
+				if k >= 0 {
+					node.Content[k+1] = value
+				} else {
+					node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name, Line: number, Column: 1}, value)
+				}
+				break
+			}
+			if k < 0 || node.Content[k+1].Kind != yaml.MappingNode {
+				child := &yaml.Node{Kind: yaml.MappingNode, Line: number, Column: 1}
+				if k >= 0 {
+					node.Content[k+1] = child
+				} else {
+					node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name, Line: number, Column: 1}, child)
+				}
+				node = child
+				continue
+			}
+			node = node.Content[k+1]
+		}
+	}
+	return root, nil
+}
+
+// envValue scalar node from dotenv value
+// Double quoted values are unquoted with escapes, single quoted are taken as is, inline comments are removed from unquoted
+func envValue(raw string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode}
+	switch {
+	case len(raw) >= 2 && raw[0] == '"':
+		end := strings.LastIndex(raw, "\"")
+		value, err := strconv.Unquote(raw[:end+1])
+		if end == 0 || err != nil {
+			return nil, errors.New("invalid double quoted value")
+		}
+		node.Value, node.Tag, node.Style = value, "!!str", yaml.DoubleQuotedStyle
+	case len(raw) >= 2 && raw[0] == '\'':
+		end := strings.LastIndex(raw, "'")
+		if end == 0 {
+			return nil, errors.New("invalid single quoted value")
+		}
+		node.Value, node.Tag, node.Style = raw[1:end], "!!str", yaml.SingleQuotedStyle
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		node.Value = raw
+		if raw == "" {
+			node.Tag = "!!null"
+		}
+	}
+	return node, nil
+}
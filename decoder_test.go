@@ -0,0 +1,268 @@
+package gocli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeEnvConfig(t *testing.T) {
+	t.Run("nested", func(t *testing.T) {
+		root, err := DecodeEnvConfig([]byte("# comment\nexport PROJECT__NAME=dna\nweb.port=8080 # inline\nNOTE=\"a # b\\n\"\nDEPENDS=global, secrets\nEMPTY=\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cfg struct {
+			testConfig `yaml:",inline"`
+			Depends    []string
+			Empty      *string
+		}
+		if err = root.Decode(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Project.Name != "dna" || cfg.Web.Port != 8080 || cfg.Note != "a # b\n" || cfg.Empty != nil {
+			t.Fatal("wrong config", cfg)
+		}
+		if strings.Join(cfg.Depends, ",") != "global,secrets" {
+			t.Fatal("wrong depends", cfg.Depends)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := DecodeEnvConfig([]byte("A=1\nB\n"))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 2:1: ") {
+			t.Fatal("must be line error", err)
+		}
+		_, err = DecodeEnvConfig([]byte("A=1\nB=\"1\n"))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 2:3: ") {
+			t.Fatal("must be quote error", err)
+		}
+	})
+}
+
+func TestDecodeJsonConfig(t *testing.T) {
+	root, err := DecodeJsonConfig([]byte("{\n  \"web\": {\"port\": 8080},\n  \"features\": [\"a\"]\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg testConfig
+	if err = root.Decode(&cfg); err != nil || cfg.Web.Port != 8080 || cfg.Features[0] != "a" {
+		t.Fatal("wrong config", err)
+	}
+	t.Run("substitution", func(t *testing.T) {
+		t.Setenv("GOCLI_TEST_PORT", "9090")
+		dir := writeTestConfigs(t, map[string]string{
+			"local.json": "{\"web\": {\"port\": \"${GOCLI_TEST_PORT}\"}, \"note\": \"8080\", \"features\": [\"${GOCLI_TEST_PORT}\", \"port ${GOCLI_TEST_PORT}\"]}",
+		})
+		var cfg testConfig
+		app, e := NewApplicationE("local", dir, &cfg)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Web.Port != 9090 || cfg.Note != "8080" || strings.Join(cfg.Features, ",") != "9090,port 9090" {
+			t.Fatal("wrong substitution", cfg)
+		}
+		data, _ := app.EffectiveConfig()
+		if !strings.Contains(string(data), `"port": 9090`) || !strings.Contains(string(data), `"note": "8080"`) {
+			t.Fatal("single variable must be typed, other strings must be kept", string(data))
+		}
+	})
+	_, err = DecodeJsonConfig([]byte("{\n  \"web\": {\"port\": 8080},\n}"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:1: ") {
+		t.Fatal("must be line error", err)
+	}
+	_, err = DecodeJsonConfig([]byte("{\"web\": {\"port\": 8080, \"host\" \"x\"}}"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 1:31: ") {
+		t.Fatal("must be column error", err)
+	}
+}
+
+func TestDecodeTomlConfig(t *testing.T) {
+	t.Run("types", func(t *testing.T) {
+		root, err := DecodeTomlConfig([]byte(`# comment
+depends = ["global"]
+note = """
+multi \
+  line"""
+
+[project]
+name = 'd"na' # inline
+"debug" = true
+
+[web]
+port = 8_080
+host = "0.0.0.0\t"
+
+[[servers]]
+ip = "10.0.0.1"
+tags = [
+  "a", # first
+  "b",
+]
+[[servers]]
+ip = "10.0.0.2"
+limits = { cpu = 1.5, mem.size = 0x10 }
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cfg struct {
+			testConfig `yaml:",inline"`
+			Depends    []string
+			Servers    []struct {
+				Ip     string
+				Tags   []string
+				Limits struct {
+					Cpu float64
+					Mem struct {
+						Size int
+					}
+				}
+			}
+		}
+		if err = root.Decode(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Project.Name != `d"na` || !cfg.Project.Debug || cfg.Web.Port != 8080 || cfg.Web.Host != "0.0.0.0\t" {
+			t.Fatal("wrong config", cfg.testConfig)
+		}
+		if cfg.Note != "multi line" || len(cfg.Depends) != 1 {
+			t.Fatal("wrong note or depends", cfg.Note, cfg.Depends)
+		}
+		if len(cfg.Servers) != 2 || len(cfg.Servers[0].Tags) != 2 || cfg.Servers[1].Limits.Cpu != 1.5 || cfg.Servers[1].Limits.Mem.Size != 16 {
+			t.Fatal("wrong servers", cfg.Servers)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for content, line := range map[string]string{
+			"a = 1\nb = \"x\n":   "line 2:5: ",
+			"a = 1\na = 2\n":     "line 2:1: ",
+			"[a]\nb = yes\n":     "line 2:5: ",
+			"a = 1 b = 2\n":      "line 1:5: ",
+			"a = [1, 2\n\nb = 3": "line 3:1: ",
+		} {
+			if _, err := DecodeTomlConfig([]byte(content)); err == nil || !strings.HasPrefix(err.Error(), line) {
+				t.Fatal("must be line error", content, err)
+			}
+		}
+	})
+	t.Run("tables", func(t *testing.T) {
+		for content, result := range map[string]string{
+			"[a.b]\nc = 1\n[a]\nd = 2\n":                                          "map[a:map[b:map[c:1] d:2]]",
+			"[[a]]\nb = 1\n[a.c]\nd = 2\n[[a]]\nb = 3\n":                          "map[a:[map[b:1 c:map[d:2]] map[b:3]]]",
+			"fruit.apple.color = \"red\"\n[fruit.apple.texture]\nsmooth = true\n": "map[fruit:map[apple:map[color:red texture:map[smooth:true]]]]",
+			"a = { b.c = 1, d = { e = 2 } }\n":                                    "map[a:map[b:map[c:1] d:map[e:2]]]",
+			"\"a.b\" = 1\n'c d'.e = 2\n[\"f g\".h]\ni = 3\n":                      "map[a.b:1 c d:map[e:2] f g:map[h:map[i:3]]]",
+			"[a]\nb.c = 1\nb.d = 2\n":                                             "map[a:map[b:map[c:1 d:2]]]",
+		} {
+			root, err := DecodeTomlConfig([]byte(content))
+			if err != nil {
+				t.Fatal(content, err)
+			}
+			var value interface{}
+			if err = root.Decode(&value); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(value) != result {
+				t.Fatal("wrong tables", content, fmt.Sprint(value))
+			}
+		}
+	})
+	t.Run("redefinition", func(t *testing.T) {
+		for content, line := range map[string]string{
+			"[a]\nb = 1\n[a]\n":                      "line 3:2: table a is already defined",
+			"[[a]]\nb = 1\n[a]\n":                    "line 3:2: table a is already defined",
+			"[a]\nb = 1\n[[a]]\n":                    "line 3:3: key a is not an array of tables",
+			"a = [1]\n[[a]]\n":                       "line 2:3: key a is not an array of tables",
+			"a = { b = 1 }\n[a]\n":                   "line 2:2: table a is already defined",
+			"a = { b = 1 }\na.c = 2\n":               "line 2:1: inline table a can not be extended",
+			"a = { b = { c = 1 } }\n[a.b.d]\n":       "line 2:2: inline table a can not be extended",
+			"fruit.apple.color = 1\n[fruit.apple]\n": "line 2:8: table fruit.apple is already defined",
+			"[a.b.c]\nd = 1\n[a]\nb.c.e = 2\n":       "line 4:1: table b is defined by header and can not be extended by dotted key",
+			"a.b = 1\na.b.c = 2\n":                   "line 2:3: key a.b is not a table",
+			"a = 1\n[a]\n":                           "line 2:2: table a is already defined",
+			"a = { b = 1, }\n":                       "line 1:14: ",
+		} {
+			if _, err := DecodeTomlConfig([]byte(content)); err == nil || !strings.HasPrefix(err.Error(), line) {
+				t.Fatal("must be redefinition error", content, err)
+			}
+		}
+	})
+	t.Run("strings", func(t *testing.T) {
+		root, err := DecodeTomlConfig([]byte(`basic = """
+a "quoted" \
+   b\u00e9"""
+literal = '''
+C:\dir\
+line'''
+quotes = """"a"""""
+single = ''''b'''''
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var value map[string]string
+		if err = root.Decode(&value); err != nil {
+			t.Fatal(err)
+		}
+		if value["basic"] != "a \"quoted\" bé" || value["literal"] != "C:\\dir\\\nline" {
+			t.Fatal("wrong multi-line strings", value)
+		}
+		if value["quotes"] != `"a""` || value["single"] != "'b''" {
+			t.Fatal("wrong closing quotes", value)
+		}
+		if _, err = DecodeTomlConfig([]byte("\"\"\"a\"\"\" = 1\n")); err == nil || !strings.HasPrefix(err.Error(), "line 1:1: ") {
+			t.Fatal("multi-line key must be invalid", err)
+		}
+	})
+}
+
+func TestDNApp_SetConfigDecoder(t *testing.T) {
+	t.Setenv("GOCLI_TEST_HOST", "localhost")
+	t.Setenv("GOCLI_TEST_PORT", "9090")
+	// variables are substituted in decoded json values so value can not inject keys
+	t.Setenv("GOCLI_TEST_NOTE", "a\", \"features\": [\"x\"], \"b\": \"")
+	dir := writeTestConfigs(t, map[string]string{
+		"global.yml":   "project:\n  name: dna\nweb:\n  port: 8080\n",
+		"secrets.env":  "DEPENDS=global\nWEB__HOST=${GOCLI_TEST_HOST}\n",
+		"local.json":   "{\"depends\": [\"secrets\", \"feature\", \"web\"], \"project\": {\"debug\": true}, \"note\": \"${GOCLI_TEST_NOTE}\"}",
+		"feature.list": "a\nb\n",
+		"web.toml":     "[web]\nport = ${GOCLI_TEST_PORT}\n",
+	})
+	decoder := WithConfigDecoder(".list", ConfigDecoderFunc(func(data []byte) (*yaml.Node, error) {
+		root := &yaml.Node{Kind: yaml.MappingNode}
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range strings.Fields(string(data)) {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "features"}, list)
+		return root, nil
+	}))
+	var cfg testConfig
+	app, e := NewApplicationE("local", dir, &cfg, decoder)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if cfg.Project.Name != "dna" || !cfg.Project.Debug || cfg.Web.Port != 9090 || cfg.Web.Host != "localhost" {
+		t.Fatal("wrong config", cfg)
+	}
+	if cfg.Note != os.Getenv("GOCLI_TEST_NOTE") {
+		t.Fatal("substituted json value must be string", cfg.Note)
+	}
+	if strings.Join(cfg.Features, ",") != "a,b" {
+		t.Fatal("wrong features", cfg.Features)
+	}
+	if !strings.HasSuffix(app.GetConfigPath("missing"), "missing.yaml") {
+		t.Fatal("yaml path must be default")
+	}
+	// env config in registered format is decoded on first parse
+	var feature testConfig
+	if _, e = NewApplicationE("feature", dir, &feature, decoder); e != nil || strings.Join(feature.Features, ",") != "a,b" {
+		t.Fatal("env config must be decoded with registered decoder", e, feature.Features)
+	}
+	if _, e = NewApplicationE("feature", dir, &testConfig{}); e == nil {
+		t.Fatal("config without registered decoder must not be found")
+	}
+}
@@ -0,0 +1,178 @@
+package gocli
+
+import (
+	"flag"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dimonrus/porterr"
+)
+
+// registerArguments define flags for arguments in flag set
+// Arguments without env are bound to prefixed environment variable if prefix is not empty
+func registerArguments(set *flag.FlagSet, args ArgumentMap, envPrefix string) porterr.IError {
+	for _, key := range args.keys() {
+		argument := args[key]
+		argument.Name = key
+		if argument.Env == "" && envPrefix != "" {
+			argument.Env = EnvName(envPrefix, key)
+		}
+		switch argument.Type {
+		case ArgumentTypeString, ArgumentTypePath:
+			var value string
+			argument.Value = &value
+			set.StringVar(&value, key, "", argument.Label)
+		case ArgumentTypeInt:
+			var value int64
+			argument.Value = &value
+			set.Int64Var(&value, key, 0, argument.Label)
+		case ArgumentTypeUint:
+			var value uint64
+			argument.Value = &value
+			set.Uint64Var(&value, key, 0, argument.Label)
+		case ArgumentTypeFloat:
+			var value float64
+			argument.Value = &value
+			set.Float64Var(&value, key, 0, argument.Label)
+		case ArgumentTypeBool:
+			var value bool
+			argument.Value = &value
+			set.BoolVar(&value, key, false, argument.Label)
+		default:
+			value, pointer, e := newArgumentValue(argument)
+			if e != nil {
+				return e
+			}
+			argument.Value = pointer
+			set.Var(value, key, argument.Label)
+		}
+		if argument.Default != nil {
+			def, e := Argument{Name: key, Value: argument.Default}.AsString()
+			if e != nil {
+				return e
+			}
+			set.Lookup(key).DefValue = def
+		}
+		args[key] = argument
+	}
+	return nil
+}
+
+// bindArguments apply env and default values to arguments not passed in flag set and validate them
+// Precedence: flag > env > default > zero value
+func bindArguments(set *flag.FlagSet, args ArgumentMap) porterr.IError {
+	passed := make(map[string]struct{})
+	set.Visit(func(f *flag.Flag) {
+		passed[f.Name] = struct{}{}
+	})
+	e := porterr.New(porterr.PortErrorValidation, "Arguments are invalid")
+	for _, key := range args.keys() {
+		argument := args[key]
+		f := set.Lookup(key)
+		if f == nil {
+			continue
+		}
+		argument.source = ArgumentSourceZero
+		if _, ok := passed[key]; ok {
+			argument.source = ArgumentSourceFlag
+		}
+		if argument.source == ArgumentSourceZero && argument.Env != "" {
+			if value, ok := os.LookupEnv(argument.Env); ok {
+				if err := f.Value.Set(value); err != nil {
+					e = e.PushDetail(porterr.PortErrorArgument, key, "env "+argument.Env+": "+err.Error())
+					continue
+				}
+				argument.source = ArgumentSourceEnv
+			}
+		}
+		if argument.source == ArgumentSourceZero && argument.Default != nil {
+			if err := f.Value.Set(f.DefValue); err != nil {
+				e = e.PushDetail(porterr.PortErrorArgument, key, "default: "+err.Error())
+				continue
+			}
+			argument.source = ArgumentSourceDefault
+		}
+		args[key] = argument
+		if argument.source == ArgumentSourceZero {
+			if argument.Required {
+				e = e.PushDetail(porterr.PortErrorArgument, key, "argument is required")
+			}
+			continue
+		}
+		if message := argument.validate(); message != "" {
+			e = e.PushDetail(porterr.PortErrorArgument, key, message)
+		}
+	}
+	return e.IfDetails()
+}
+
+// EnvName Environment variable name for argument with prefix. Example: DNA_COUNT
+func EnvName(prefix string, name string) string {
+	name = strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	if prefix == "" {
+		return name
+	}
+	return strings.ToUpper(strings.TrimRight(prefix, "_")) + "_" + name
+}
+
+// keys sorted argument names
+func (a ArgumentMap) keys() []string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validate check min, max and pattern rules. Returns violation message
+// Min and max are compared with numeric value, duration seconds, string length or items count
+func (a Argument) validate() string {
+	if a.Min != nil || a.Max != nil {
+		var measure float64
+		var e porterr.IError
+		switch a.Type {
+		case ArgumentTypeInt, ArgumentTypeUint, ArgumentTypeFloat:
+			measure, e = a.AsFloat()
+		case ArgumentTypeDuration:
+			measure = a.DurationOr(0).Seconds()
+		case ArgumentTypeString, ArgumentTypeEnum, ArgumentTypePath:
+			measure = float64(utf8.RuneCountInString(a.StringOr("")))
+		case ArgumentTypeStringSlice, ArgumentTypeIntSlice:
+			var items []string
+			items, e = a.AsStrings()
+			measure = float64(len(items))
+		case ArgumentTypeMap:
+			value, _ := a.AsMap()
+			measure = float64(len(value))
+		}
+		if e != nil {
+			return e.Error()
+		}
+		if a.Min != nil && measure < *a.Min {
+			return "must be greater than or equal to " + formatFloat(*a.Min)
+		}
+		if a.Max != nil && measure > *a.Max {
+			return "must be less than or equal to " + formatFloat(*a.Max)
+		}
+	}
+	if a.Pattern != "" {
+		re, err := regexp.Compile(a.Pattern)
+		if err != nil {
+			return "pattern is invalid: " + err.Error()
+		}
+		items := []string{a.StringOr("")}
+		if a.Type == ArgumentTypeStringSlice {
+			items, _ = a.AsStrings()
+		}
+		for _, item := range items {
+			if !re.MatchString(item) {
+				return item + " does not match pattern " + a.Pattern
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package gocli
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimonrus/gohelp"
+	"github.com/dimonrus/porterr"
+	"gopkg.in/yaml.v3"
+)
+
+const testArgumentsYaml = `
+count:
+  type: int
+  default: 10
+  min: 1
+  max: 100
+name:
+  type: string
+  required: true
+  pattern: ^[a-z]+$
+timeout:
+  type: duration
+  default: 5s
+tags:
+  type: string[]
+  default: [a, b]
+host:
+  type: string
+  env: GOCLI_TEST_HOST
+mode:
+  type: enum
+  enum: [sync, async]
+  default: sync
+`
+
+func parseTestArguments(t *testing.T, args ...string) (ArgumentMap, porterr.IError) {
+	var am ArgumentMap
+	if err := yaml.Unmarshal([]byte(testArgumentsYaml), &am); err != nil {
+		t.Fatal(err)
+	}
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	if e := registerArguments(set, am, ""); e != nil {
+		t.Fatal(e)
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return am, bindArguments(set, am)
+}
+
+func TestBindArguments(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		_ = os.Setenv("GOCLI_TEST_HOST", "db.local")
+		defer os.Unsetenv("GOCLI_TEST_HOST")
+		am, e := parseTestArguments(t, "-name=orders", "-tags=c")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if am["count"].IntOr(0) != 10 || am["timeout"].DurationOr(0) != time.Second*5 || am["mode"].StringOr("") != "sync" {
+			t.Fatal("default values must be applied")
+		}
+		if tags := MustGet[[]string](am, "tags"); len(tags) != 1 || tags[0] != "c" {
+			t.Fatal("passed value must replace default", tags)
+		}
+		if am["host"].StringOr("") != "db.local" {
+			t.Fatal("env value must be applied")
+		}
+	})
+	t.Run("validation", func(t *testing.T) {
+		_, e := parseTestArguments(t, "-count=1000", "-tags=c")
+		if e == nil {
+			t.Fatal("must be validation error")
+		}
+		if len(e.GetDetails()) != 2 {
+			t.Fatal("all violations must be reported", e.GetDetails())
+		}
+	})
+}
+
+func TestArgument_validate(t *testing.T) {
+	argument := Argument{Type: ArgumentTypeString, Value: gohelp.Ptr("Orders"), Pattern: "^[a-z]+$"}
+	if !strings.Contains(argument.validate(), "does not match") {
+		t.Fatal("pattern must be checked")
+	}
+	argument = Argument{Type: ArgumentTypeStringSlice, Value: &[]string{"a"}, Min: gohelp.Ptr(2.0)}
+	if argument.validate() == "" {
+		t.Fatal("items count must be checked")
+	}
+	argument = Argument{Type: ArgumentTypeDuration, Value: gohelp.Ptr(time.Minute), Max: gohelp.Ptr(30.0)}
+	if argument.validate() == "" {
+		t.Fatal("duration seconds must be checked")
+	}
+}
+
+func TestBindArguments_Source(t *testing.T) {
+	_ = os.Setenv("DNA_COUNT", "20")
+	_ = os.Setenv("DNA_NAME", "env")
+	defer os.Unsetenv("DNA_COUNT")
+	defer os.Unsetenv("DNA_NAME")
+	args := ArgumentMap{
+		"count":   {Type: ArgumentTypeInt, Default: 10},
+		"name":    {Type: ArgumentTypeString},
+		"timeout": {Type: ArgumentTypeDuration, Default: "5s"},
+		"part":    {Type: ArgumentTypeFloat},
+		"host":    {Type: ArgumentTypeString, Env: "GOCLI_TEST_HOST"},
+	}
+	app := &DNApp{}
+	app.SetEnvPrefix("dna")
+	e := app.ParseFlagsFrom([]string{"-name=flag"}, args)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if args["name"].StringOr("") != "flag" || args["name"].Source() != ArgumentSourceFlag {
+		t.Fatal("flag must have priority")
+	}
+	if args["count"].IntOr(0) != 20 || args["count"].Source() != ArgumentSourceEnv || args["count"].Env != "DNA_COUNT" {
+		t.Fatal("env must have priority over default")
+	}
+	if args["timeout"].DurationOr(0) != time.Second*5 || args["timeout"].Source() != ArgumentSourceDefault {
+		t.Fatal("default must be applied")
+	}
+	if args["part"].Source() != ArgumentSourceZero {
+		t.Fatal("zero source expected")
+	}
+	if args["host"].Env != "GOCLI_TEST_HOST" {
+		t.Fatal("explicit env must be kept")
+	}
+	if EnvName("dna_", "max-count") != "DNA_MAX_COUNT" {
+		t.Fatal("wrong env name")
+	}
+}
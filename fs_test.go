@@ -0,0 +1,63 @@
+package gocli
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewApplicationFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/global.yaml": {Data: []byte("project:\n  name: dna\nweb:\n  port: 8080\n  host: 0.0.0.0\n")},
+		"config/local.json":  {Data: []byte("{\"depends\": \"global\", \"project\": {\"debug\": true}}")},
+	}
+	t.Run("embedded", func(t *testing.T) {
+		var cfg testConfig
+		app := NewApplicationFS("local", fsys, "config", &cfg)
+		if cfg.Project.Name != "dna" || !cfg.Project.Debug || cfg.Web.Port != 8080 {
+			t.Fatal("wrong config", cfg)
+		}
+		if app.(*DNApp).GetConfigPath("local") != "config/local.json" {
+			t.Fatal("wrong config path", app.(*DNApp).GetConfigPath("local"))
+		}
+	})
+	t.Run("override", func(t *testing.T) {
+		override := writeTestConfigs(t, map[string]string{
+			"global.yaml": "web:\n  port: 9000\n",
+			"local.yaml":  "project:\n  name: override\n",
+			"prod.yaml":   "depends: global\nnote: prod\n",
+		})
+		var cfg testConfig
+		NewApplicationFS("local", fsys, "config", &cfg, override)
+		if cfg.Project.Name != "override" || !cfg.Project.Debug || cfg.Web.Port != 9000 || cfg.Web.Host != "0.0.0.0" {
+			t.Fatal("wrong config", cfg)
+		}
+		cfg = testConfig{}
+		NewApplicationFS("prod", fsys, "config", &cfg, override)
+		if cfg.Note != "prod" || cfg.Web.Port != 9000 || cfg.Project.Name != "dna" {
+			t.Fatal("override only config must be loaded", cfg)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		var cfg testConfig
+		_, e := NewApplicationFSE("prod", fsys, "config", &cfg)
+		if e == nil || !strings.Contains(e.Error(), "config/prod.yaml") {
+			t.Fatal("must be missing config error", e)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		override := writeTestConfigs(t, map[string]string{
+			"local.yaml": "project:\n  name: [override\n",
+		})
+		var cfg testConfig
+		_, e := NewApplicationFSE("local", fsys, "config", &cfg, WithConfigOverride(override))
+		if e == nil || !strings.Contains(e.Error(), "local.yaml") {
+			t.Fatal("must be override syntax error", e)
+		}
+		cfg = testConfig{}
+		_, e = NewApplicationFSE("local", fsys, "config", &cfg, WithConfigOverride(t.TempDir()), WithStrictConfig(true))
+		if e != nil || cfg.Project.Name != "dna" {
+			t.Fatal("options must be applied", e, cfg)
+		}
+	})
+}
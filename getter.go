@@ -0,0 +1,149 @@
+package gocli
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/dimonrus/porterr"
+)
+
+// ArgumentValue types supported by generic argument getters
+type ArgumentValue interface {
+	~string | ~bool |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 |
+		time.Time | []string | []int64 | map[string]string
+}
+
+// ArgumentSource container of arguments. Implemented by ArgumentMap and Arguments
+type ArgumentSource interface {
+	// GetByName get argument by name
+	GetByName(name string) *Argument
+}
+
+// Get Get argument value converted to T with As* conversion rules of Argument
+func Get[T ArgumentValue](args ArgumentSource, name string) (T, porterr.IError) {
+	argument := args.GetByName(name)
+	if argument == nil {
+		var result T
+		return result, porterr.New(porterr.PortErrorArgument, "argument "+name+" is not found")
+	}
+	return getValue[T](*argument)
+}
+
+// MustGet Get argument value converted to T. Panics on error
+func MustGet[T ArgumentValue](args ArgumentSource, name string) T {
+	result, e := Get[T](args, name)
+	if e != nil {
+		panic(e)
+	}
+	return result
+}
+
+// getValue Get argument value converted to T
+func getValue[T ArgumentValue](argument Argument) (T, porterr.IError) {
+	var result T
+	e := setValue(reflect.ValueOf(&result).Elem(), argument)
+	return result, e
+}
+
+// setValue Convert argument value and set it to v
+func setValue(v reflect.Value, argument Argument) porterr.IError {
+	switch v.Type() {
+	case durationType:
+		value, e := argument.AsDuration()
+		if e != nil {
+			return e
+		}
+		v.SetInt(int64(value))
+		return nil
+	case timeType:
+		value, e := argument.AsTime()
+		if e != nil {
+			return e
+		}
+		v.Set(reflect.ValueOf(value))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, e := argument.AsInt()
+		if e != nil {
+			return e
+		}
+		if v.OverflowInt(value) {
+			return porterr.NewF(porterr.PortErrorType, "argument %s: %v overflows %s", argument.Name, value, v.Type())
+		}
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, e := argument.AsUint()
+		if e != nil {
+			return e
+		}
+		if v.OverflowUint(value) {
+			return porterr.NewF(porterr.PortErrorType, "argument %s: %v overflows %s", argument.Name, value, v.Type())
+		}
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, e := argument.AsFloat()
+		if e != nil {
+			return e
+		}
+		if v.OverflowFloat(value) {
+			return porterr.NewF(porterr.PortErrorType, "argument %s: %v overflows %s", argument.Name, value, v.Type())
+		}
+		v.SetFloat(value)
+	case reflect.Bool:
+		value, e := argument.AsBool()
+		if e != nil {
+			return e
+		}
+		v.SetBool(value)
+	case reflect.String:
+		value, e := argument.AsString()
+		if e != nil {
+			return e
+		}
+		v.SetString(value)
+	case reflect.Slice:
+		var items []string
+		var e porterr.IError
+		if v.Type().Elem().Kind() == reflect.String {
+			items, e = argument.AsStrings()
+		} else {
+			var ints []int64
+			ints, e = argument.AsInts()
+			for _, i := range ints {
+				items = append(items, strconv.FormatInt(i, 10))
+			}
+		}
+		if e != nil {
+			return e
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i := range items {
+			if e = setValue(slice.Index(i), Argument{Name: argument.Name, Value: items[i]}); e != nil {
+				return e
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() != reflect.String {
+			return porterr.NewF(porterr.PortErrorType, "argument %s: %s is not supported", argument.Name, v.Type())
+		}
+		value, e := argument.AsMap()
+		if e != nil {
+			return e
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(value))
+		for key, item := range value {
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), reflect.ValueOf(item).Convert(v.Type().Elem()))
+		}
+		v.Set(m)
+	default:
+		return porterr.NewF(porterr.PortErrorType, "argument %s: %s is not supported", argument.Name, v.Type())
+	}
+	return nil
+}
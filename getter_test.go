@@ -0,0 +1,61 @@
+package gocli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dimonrus/gohelp"
+)
+
+func TestGet(t *testing.T) {
+	am := ArgumentMap{
+		"count": {Type: ArgumentTypeUint, Value: gohelp.Ptr(uint64(10))},
+		"name":  {Type: ArgumentTypeString, Value: gohelp.Ptr("orders")},
+		"big":   {Type: ArgumentTypeInt, Value: gohelp.Ptr(int64(1000))},
+	}
+	t.Run("map", func(t *testing.T) {
+		count, e := Get[int](am, "count")
+		if e != nil || count != 10 {
+			t.Fatal("wrong int conversion")
+		}
+		part, e := Get[float32](am, "count")
+		if e != nil || part != 10 {
+			t.Fatal("wrong float conversion")
+		}
+		timeout, e := Get[time.Duration](am, "count")
+		if e != nil || timeout != 10 {
+			t.Fatal("named types must be supported")
+		}
+		if MustGet[string](am, "name") != "orders" {
+			t.Fatal("wrong string")
+		}
+	})
+	t.Run("list", func(t *testing.T) {
+		list := am.ToList()
+		count, e := Get[uint8](list, "count")
+		if e != nil || count != 10 {
+			t.Fatal("wrong uint conversion")
+		}
+		name, e := Get[string](list, "count")
+		if e != nil || name != "10" {
+			t.Fatal("wrong string conversion")
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if _, e := Get[int8](am, "big"); e == nil {
+			t.Fatal("must be overflow error")
+		}
+		if _, e := Get[int](am, "name"); e == nil {
+			t.Fatal("must be type error")
+		}
+		if _, e := Get[int](am, "unknown"); e == nil {
+			t.Fatal("must be not found error")
+		}
+		defer func() {
+			if recover() == nil {
+				t.Fatal("must panic")
+			}
+		}()
+		MustGet[bool](am, "unknown")
+	})
+}
@@ -0,0 +1,70 @@
+package gocli
+
+import (
+	"bytes"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dimonrus/porterr"
+)
+
+const (
+	// CommandHelp Built-in help command name
+	CommandHelp = "help"
+)
+
+// Help Render help for all registered commands or for command path
+func (r *CommandRouter) Help(path string) (string, porterr.IError) {
+	path = strings.Join(strings.Fields(path), " ")
+	routes := r.Routes()
+	buf := bytes.NewBuffer(nil)
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	if path == "" {
+		buf.WriteString("Available commands:\n")
+		for _, route := range routes {
+			writeRouteHelp(w, route)
+		}
+	} else {
+		var found bool
+		for _, route := range routes {
+			if route.Path == path || strings.HasPrefix(route.Path, path+" ") {
+				writeRouteHelp(w, route)
+				found = true
+			}
+		}
+		if !found {
+			return "", porterr.New(porterr.PortErrorCommand, "Unknown command: "+path)
+		}
+	}
+	_ = w.Flush()
+	return buf.String(), nil
+}
+
+// help built-in help command handler
+func (r *CommandRouter) help(command *Command, args Arguments) porterr.IError {
+	names := make([]string, 0, len(args))
+	for _, argument := range args {
+		names = append(names, argument.Name)
+	}
+	message, e := r.Help(strings.Join(names, " "))
+	if e != nil {
+		return e
+	}
+	return command.Result([]byte(message))
+}
+
+// writeRouteHelp write route usage with arguments
+func writeRouteHelp(w *tabwriter.Writer, route CommandRoute) {
+	usage := route.Path
+	for _, argument := range route.Arguments {
+		usage += " <" + argument.Name + ">"
+	}
+	_, _ = w.Write([]byte("  " + usage + "\t" + route.Description + "\n"))
+	for _, argument := range route.Arguments {
+		argumentType := argument.Type
+		if argumentType == "" {
+			argumentType = ArgumentTypeString
+		}
+		_, _ = w.Write([]byte("      " + argument.Name + "\t" + argumentType + "\t" + argument.Label + "\n"))
+	}
+}
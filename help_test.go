@@ -0,0 +1,42 @@
+package gocli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dimonrus/porterr"
+)
+
+func TestCommandRouter_Help(t *testing.T) {
+	router := NewCommandRouter()
+	router.Handle("consumer stop", func(command *Command, args Arguments) porterr.IError {
+		return nil
+	}, Argument{Name: "name", Type: ArgumentTypeString, Label: "consumer name"}).SetDescription("Stop consumer")
+	router.Handle("cache flush", func(command *Command, args Arguments) porterr.IError {
+		return nil
+	}).SetDescription("Flush cache")
+
+	t.Run("all", func(t *testing.T) {
+		message, e := router.Help("")
+		if e != nil {
+			t.Fatal(e)
+		}
+		for _, s := range []string{"help", "cache flush", "Flush cache", "consumer stop <name>", "Stop consumer", "consumer name"} {
+			if !strings.Contains(message, s) {
+				t.Fatal("help must contain " + s)
+			}
+		}
+	})
+	t.Run("command", func(t *testing.T) {
+		command, r, closer := pipeCommand(t, "help consumer")
+		defer closer()
+		go router.Serve(command)
+		line, _, _ := r.ReadLine()
+		if !strings.Contains(string(line), "consumer stop <name>") {
+			t.Fatal("wrong help reply", string(line))
+		}
+		if _, e := router.Help("unknown"); e == nil {
+			t.Fatal("must be unknown command error")
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package gocli
+
+import (
+	"github.com/dimonrus/porterr"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MergeStrategyDeep Merge mapping keys recursively. Default for mappings
+	MergeStrategyDeep = "merge"
+	// MergeStrategyReplace Replace inherited value. Default for lists and scalars
+	MergeStrategyReplace = "replace"
+	// MergeStrategyAppend Append list items to inherited list
+	MergeStrategyAppend = "append"
+
+	// ConfigTagMerge Tag to merge value with inherited value
+	ConfigTagMerge = "!merge"
+	// ConfigTagReplace Tag to replace inherited value
+	ConfigTagReplace = "!replace"
+	// ConfigTagAppend Tag to append list to inherited list
+	ConfigTagAppend = "!append"
+	// ConfigTagReset Tag to drop inherited value. Key without value is reset to zero value
+	ConfigTagReset = "!reset"
+	// ConfigTagDelete Tag to delete inherited key
+	ConfigTagDelete = "!delete"
+)
+
+// SetMergeStrategy Set merge strategy for config key path. Example: app.SetMergeStrategy("web.hosts", MergeStrategyAppend)
+// Strategy is applied on next ParseConfig or reload. Use WithMergeStrategy to apply it on first parse
+func (a *DNApp) SetMergeStrategy(path string, strategy string) {
+	if a.mergeStrategies == nil {
+		a.mergeStrategies = make(map[string]string)
+	}
+	a.mergeStrategies[path] = strategy
+}
+
+// WithMergeStrategy Set merge strategy for config key path before config is parsed
+func WithMergeStrategy(path string, strategy string) ApplicationOption {
+	return func(app *DNApp) {
+		app.SetMergeStrategy(path, strategy)
+	}
+}
+
+// EffectiveConfig Render merged config as yaml. Secret values are masked
+func (a *DNApp) EffectiveConfig() ([]byte, porterr.IError) {
+	a.configMutex.RLock()
+	defer a.configMutex.RUnlock()
+	if a.config.masked == nil {
+		return []byte{}, nil
+	}
+	data, err := yaml.Marshal(a.config.masked)
+	if err != nil {
+		return nil, porterr.New(porterr.PortErrorEncoder, err.Error())
+	}
+	return data, nil
+}
+
+// mergeConfig merge override node over base node. Nil returned if value must be deleted
+func (a *DNApp) mergeConfig(base *yaml.Node, override *yaml.Node, path string) *yaml.Node {
+	if override == nil {
+		return base
+	}
+	strategy, ok := a.mergeStrategies[path]
+	switch override.Tag {
+	case ConfigTagDelete:
+		return nil
+	case ConfigTagReset, ConfigTagReplace:
+		strategy = MergeStrategyReplace
+	case ConfigTagAppend:
+		strategy = MergeStrategyAppend
+	case ConfigTagMerge:
+		strategy = MergeStrategyDeep
+	default:
+		if !ok {
+			strategy = MergeStrategyDeep
+		}
+	}
+	if base == nil || strategy == MergeStrategyReplace || base.Kind != override.Kind {
+		return cloneNode(override)
+	}
+	switch {
+	case override.Kind == yaml.MappingNode && strategy == MergeStrategyDeep:
+		result := cloneNode(base)
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key, value := override.Content[i], override.Content[i+1]
+			childPath := key.Value
+			if path != "" {
+				childPath = path + "." + key.Value
+			}
+			j := keyIndex(result, key.Value)
+			var inherited *yaml.Node
+			if j >= 0 {
+				inherited = result.Content[j+1]
+			}
+			merged := a.mergeConfig(inherited, value, childPath)
+			switch {
+			case merged == nil && j >= 0:
+				result.Content = append(result.Content[:j], result.Content[j+2:]...)
+			case merged == nil:
+			case j >= 0:
+				result.Content[j+1] = merged
+			default:
+				result.Content = append(result.Content, cloneNode(key), merged)
+			}
+		}
+		return result
+	case override.Kind == yaml.SequenceNode && strategy == MergeStrategyAppend:
+		result := cloneNode(base)
+		for _, item := range override.Content {
+			result.Content = append(result.Content, cloneNode(item))
+		}
+		return result
+	}
+	return cloneNode(override)
+}
+
+// cloneNode deep copy of node without merge tags
+func cloneNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	clone := *node
+	switch clone.Tag {
+	case ConfigTagMerge, ConfigTagReplace, ConfigTagAppend, ConfigTagReset, ConfigTagDelete:
+		clone.Tag = ""
+		if clone.Kind == yaml.ScalarNode && clone.Value == "" && clone.Style == 0 {
+			clone.Tag = "!!null"
+		}
+	}
+	if node.Content != nil {
+		clone.Content = make([]*yaml.Node, len(node.Content))
+		for i := range node.Content {
+			clone.Content[i] = cloneNode(node.Content[i])
+		}
+	}
+	return &clone
+}
+
+// keyIndex index of key in mapping node or -1
+func keyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeKey remove key from mapping node
+func removeKey(mapping *yaml.Node, key string) {
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+	if i := keyIndex(mapping, key); i >= 0 {
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+	}
+}
@@ -0,0 +1,73 @@
+package gocli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDNApp_mergeConfig(t *testing.T) {
+	files := map[string]string{
+		"global.yaml": "project:\n  name: dna\n  debug: true\nweb:\n  port: 8080\n  host: 0.0.0.0\nfeatures: [a, b]\nnote: global\n",
+	}
+	t.Run("default", func(t *testing.T) {
+		files["local.yaml"] = "depends: global\nweb:\n  port: 9000\nfeatures: [c]\n"
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: writeTestConfigs(t, files)}}
+		if e := app.parseConfig("local", nil); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Web.Port != 9000 || cfg.Web.Host != "0.0.0.0" || cfg.Project.Name != "dna" {
+			t.Fatal("mapping must be merged")
+		}
+		if len(cfg.Features) != 1 || cfg.Features[0] != "c" {
+			t.Fatal("list must be replaced")
+		}
+	})
+	t.Run("strategy", func(t *testing.T) {
+		files["local.yaml"] = "depends: global\nfeatures: [c]\n"
+		var cfg testConfig
+		_, e := NewApplicationE("local", writeTestConfigs(t, files), &cfg, WithMergeStrategy("features", MergeStrategyAppend))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if strings.Join(cfg.Features, ",") != "a,b,c" {
+			t.Fatal("list must be appended on first parse", cfg.Features)
+		}
+	})
+	t.Run("tags", func(t *testing.T) {
+		files["local.yaml"] = "depends: global\nproject: !replace\n  name: local\nweb:\n  host: !delete\n  port: !reset\nfeatures: !append [c]\nnote: !delete\n"
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: writeTestConfigs(t, files)}}
+		if e := app.parseConfig("local", nil); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Project.Name != "local" || cfg.Project.Debug {
+			t.Fatal("mapping must be replaced")
+		}
+		if cfg.Web.Port != 0 || cfg.Web.Host != "" || cfg.Note != "" {
+			t.Fatal("values must be dropped")
+		}
+		if strings.Join(cfg.Features, ",") != "a,b,c" {
+			t.Fatal("list must be appended")
+		}
+	})
+	t.Run("effective", func(t *testing.T) {
+		files["local.yaml"] = "depends: global\nweb:\n  host: !delete\nfeatures: !append [c]\n"
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: writeTestConfigs(t, files)}}
+		if e := app.parseConfig("local", nil); e != nil {
+			t.Fatal(e)
+		}
+		data, e := app.EffectiveConfig()
+		if e != nil {
+			t.Fatal(e)
+		}
+		text := string(data)
+		if strings.Contains(text, "depends") || strings.Contains(text, "host") || strings.Contains(text, "!") {
+			t.Fatal("wrong effective config", text)
+		}
+		if !strings.Contains(text, "port: 8080") || !strings.Contains(text, "features: [a, b, c]") {
+			t.Fatal("wrong effective config", text)
+		}
+	})
+}
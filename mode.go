@@ -0,0 +1,107 @@
+package gocli
+
+import (
+	"io"
+	"os"
+
+	"github.com/dimonrus/porterr"
+)
+
+// Mode application run mode. Example: web, script, consumer
+type Mode struct {
+	// Name of mode. First command line argument
+	Name string
+	// Description of mode
+	Description string
+	// Arguments of mode
+	Arguments ArgumentMap
+	// Runner mode starter
+	Runner Runner
+	// Hidden mode is not shown in usage and completion
+	Hidden bool
+}
+
+// AddMode Register application mode. Mode with same name is replaced
+func (a *DNApp) AddMode(mode Mode) Application {
+	if mode.Arguments == nil {
+		mode.Arguments = make(ArgumentMap)
+	}
+	for i := range a.modes {
+		if a.modes[i].Name == mode.Name {
+			a.modes[i] = mode
+			return a
+		}
+	}
+	a.modes = append(a.modes, mode)
+	return a
+}
+
+// GetMode Get registered mode by name
+func (a *DNApp) GetMode(name string) *Mode {
+	for i := range a.modes {
+		if a.modes[i].Name == name {
+			return &a.modes[i]
+		}
+	}
+	return nil
+}
+
+// Run Run mode chosen by first command line argument
+func (a *DNApp) Run() porterr.IError {
+	return a.RunFrom(os.Args[1:])
+}
+
+// RunFrom Run mode chosen by first argument of list. Rest of list is parsed as mode flags
+// Usage of all modes is printed when mode is unknown
+func (a *DNApp) RunFrom(args []string) porterr.IError {
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == "-h" || name == "-help" || name == "--help" {
+		a.ModeUsage(a.GetOutput())
+		return nil
+	}
+	mode := a.GetMode(name)
+	if mode == nil && name == ModeCompletion {
+		completion := a.completionMode()
+		mode = &completion
+	}
+	if mode == nil && name == ModeConfig {
+		crypt := a.configMode()
+		mode = &crypt
+	}
+	if mode == nil {
+		a.ModeUsage(a.GetOutput())
+		if name == "" {
+			return porterr.New(porterr.PortErrorArgument, "application mode is required")
+		}
+		return porterr.New(porterr.PortErrorArgument, "application mode: "+name+" is unknown")
+	}
+	e := a.parseFlags(UsageGroup{Name: mode.Name, Description: mode.Description, Arguments: mode.Arguments}, args[1:])
+	if e != nil {
+		return e
+	}
+	if mode.Runner == nil {
+		return porterr.New(porterr.PortErrorArgument, "application mode: "+name+" runner is not defined")
+	}
+	return mode.Runner(mode.Arguments.ToList()...)
+}
+
+// ModeUsage Print registered modes with arguments in usage format
+func (a *DNApp) ModeUsage(w io.Writer) {
+	a.Usage(w, a.GetUsageFormat())
+}
+
+// GetOutput Get writer for usage output. Default is os.Stderr
+func (a *DNApp) GetOutput() io.Writer {
+	if a.output == nil {
+		return os.Stderr
+	}
+	return a.output
+}
+
+// SetOutput Set writer for usage output
+func (a *DNApp) SetOutput(w io.Writer) {
+	a.output = w
+}
@@ -0,0 +1,61 @@
+package gocli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dimonrus/porterr"
+)
+
+func TestDNApp_RunFrom(t *testing.T) {
+	output := bytes.NewBuffer(nil)
+	app := &DNApp{}
+	app.SetOutput(output)
+	var consumer string
+	app.AddMode(Mode{
+		Name:        "consumer",
+		Description: "Start consumer",
+		Arguments: ArgumentMap{
+			"name": {Type: ArgumentTypeString, Label: "consumer name", Required: true},
+		},
+		Runner: func(args ...Argument) porterr.IError {
+			consumer = Arguments(args).GetByName("name").StringOr("")
+			return nil
+		},
+	}).AddMode(Mode{
+		Name:        "web",
+		Description: "Start web server",
+		Runner: func(args ...Argument) porterr.IError {
+			return nil
+		},
+	})
+
+	t.Run("run", func(t *testing.T) {
+		e := app.RunFrom([]string{"consumer", "--name=orders"})
+		if e != nil {
+			t.Fatal(e)
+		}
+		if consumer != "orders" {
+			t.Fatal("wrong mode arguments")
+		}
+		if e = app.RunFrom([]string{"consumer"}); e == nil {
+			t.Fatal("mode arguments must be validated")
+		}
+	})
+	t.Run("unknown", func(t *testing.T) {
+		output.Reset()
+		e := app.RunFrom([]string{"cron"})
+		if e == nil {
+			t.Fatal("must be unknown mode error")
+		}
+		for _, s := range []string{"consumer", "Start consumer", "-name", "consumer name", "web", "Start web server"} {
+			if !strings.Contains(output.String(), s) {
+				t.Fatal("usage must contain " + s)
+			}
+		}
+		if e = app.RunFrom(nil); e == nil {
+			t.Fatal("mode is required")
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package gocli
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dimonrus/gohelp"
+	"github.com/dimonrus/porterr"
+)
+
+// CommandHandler command processor. args contains arguments left after command path
+type CommandHandler func(command *Command, args Arguments) porterr.IError
+
+// CommandRoute registered command
+type CommandRoute struct {
+	// Path of command. Example: consumer stop
+	Path string
+	// Description of command
+	Description string
+	// Arguments schema. Positional arguments expected after path
+	Arguments Arguments
+	// path parts
+	parts []string
+	// command processor
+	handler CommandHandler
+}
+
+// SetDescription Set route description
+func (r *CommandRoute) SetDescription(description string) *CommandRoute {
+	r.Description = description
+	return r
+}
+
+// match check if command arguments starts with route path
+func (r *CommandRoute) match(args Arguments) bool {
+	if len(args) < len(r.parts) {
+		return false
+	}
+	for i := range r.parts {
+		if args[i].Name != r.parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// bind apply route schema to arguments left after path
+func (r *CommandRoute) bind(args Arguments) (Arguments, porterr.IError) {
+	if len(r.Arguments) == 0 {
+		return args, nil
+	}
+	e := porterr.New(porterr.PortErrorArgument, "Command arguments are invalid: "+r.Path)
+	result := make(Arguments, 0, len(args))
+	for i, schema := range r.Arguments {
+		if i >= len(args) {
+			e = e.PushDetail(porterr.PortErrorArgument, schema.Name, "argument is required")
+			continue
+		}
+		argument, err := castArgument(args[i], schema)
+		if err != nil {
+			e = e.PushDetail(porterr.PortErrorArgument, schema.Name, err.Error())
+			continue
+		}
+		result = append(result, argument)
+	}
+	if e = e.IfDetails(); e != nil {
+		return nil, e
+	}
+	if len(args) > len(r.Arguments) {
+		result = append(result, args[len(r.Arguments):]...)
+	}
+	return result, nil
+}
+
+// castArgument convert parsed argument to schema type
+func castArgument(arg Argument, schema Argument) (Argument, porterr.IError) {
+	raw := arg.Name
+	result := Argument{Name: schema.Name, Label: schema.Label, Type: schema.Type, Enum: schema.Enum}
+	switch schema.Type {
+	case ArgumentTypeString, ArgumentTypePath, "":
+		if result.Type == "" {
+			result.Type = ArgumentTypeString
+		}
+		result.Value = &raw
+	case ArgumentTypeInt:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return result, porterr.NewF(porterr.PortErrorArgument, "%s is not %s", raw, schema.Type)
+		}
+		result.Value = &value
+	case ArgumentTypeUint:
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return result, porterr.NewF(porterr.PortErrorArgument, "%s is not %s", raw, schema.Type)
+		}
+		result.Value = &value
+	case ArgumentTypeFloat:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return result, porterr.NewF(porterr.PortErrorArgument, "%s is not %s", raw, schema.Type)
+		}
+		result.Value = &value
+	case ArgumentTypeBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return result, porterr.NewF(porterr.PortErrorArgument, "%s is not %s", raw, schema.Type)
+		}
+		result.Value = &value
+	default:
+		value, pointer, e := newArgumentValue(schema)
+		if e != nil {
+			return result, e
+		}
+		if err := value.Set(raw); err != nil {
+			return result, porterr.NewF(porterr.PortErrorArgument, "%s is not %s: %s", raw, schema.Type, err.Error())
+		}
+		result.Value = pointer
+	}
+	return result, nil
+}
+
+// CommandRouter dispatch commands to registered handlers
+type CommandRouter struct {
+	// routes sorted by path length desc
+	routes []*CommandRoute
+	// unknown command processor
+	notFound func(command *Command)
+	// mutex for async access
+	m sync.RWMutex
+}
+
+// NewCommandRouter Create new command router with built-in help command
+func NewCommandRouter() *CommandRouter {
+	router := &CommandRouter{}
+	router.Handle(CommandHelp, router.help).SetDescription("Show available commands. Usage: help <command>")
+	return router
+}
+
+// Handle Register handler for command path with positional arguments schema
+func (r *CommandRouter) Handle(path string, handler CommandHandler, schema ...Argument) *CommandRoute {
+	parts := strings.Fields(path)
+	route := &CommandRoute{
+		Path:      strings.Join(parts, " "),
+		Arguments: schema,
+		parts:     parts,
+		handler:   handler,
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	for i := range r.routes {
+		if r.routes[i].Path == route.Path {
+			r.routes[i] = route
+			return route
+		}
+	}
+	r.routes = append(r.routes, route)
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return len(r.routes[i].parts) > len(r.routes[j].parts)
+	})
+	return route
+}
+
+// SetNotFound Set unknown command processor
+func (r *CommandRouter) SetNotFound(handler func(command *Command)) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.notFound = handler
+}
+
+// Routes Get registered routes
+func (r *CommandRouter) Routes() []CommandRoute {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	routes := make([]CommandRoute, 0, len(r.routes))
+	for _, route := range r.routes {
+		routes = append(routes, *route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+// Lookup Find route for command arguments
+func (r *CommandRouter) Lookup(args Arguments) *CommandRoute {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	for _, route := range r.routes {
+		if route.match(args) {
+			return route
+		}
+	}
+	return nil
+}
+
+// Serve Process command. Can be passed to Start as callback: app.Start(address, router.Serve)
+func (r *CommandRouter) Serve(command *Command) {
+	args := command.Arguments()
+	route := r.Lookup(args)
+	if route == nil {
+		r.m.RLock()
+		notFound := r.notFound
+		r.m.RUnlock()
+		if notFound != nil {
+			notFound(command)
+			return
+		}
+		_ = command.Result([]byte(gohelp.AnsiRed + "Unknown command: " + command.String() + gohelp.AnsiReset + "\n"))
+		return
+	}
+	args, e := route.bind(args[len(route.parts):])
+	if e == nil {
+		e = route.handler(command, args)
+	}
+	if e != nil {
+		_ = command.Result([]byte(gohelp.AnsiRed + renderError(e) + gohelp.AnsiReset + "\n"))
+	}
+}
+
+// renderError render error with details
+func renderError(e porterr.IError) string {
+	message := e.Error()
+	for _, detail := range e.GetDetails() {
+		message += "\n\t" + detail.Origin().Name + ": " + detail.Error()
+	}
+	return message
+}
@@ -0,0 +1,69 @@
+package gocli
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/dimonrus/porterr"
+)
+
+// pipeCommand parse command bound to pipe connection
+func pipeCommand(t *testing.T, raw string) (*Command, *bufio.Reader, func()) {
+	server, client := net.Pipe()
+	command := ParseCommand([]byte(raw))
+	command.BindConnection(server)
+	return command, bufio.NewReader(client), func() {
+		_ = server.Close()
+		_ = client.Close()
+	}
+}
+
+func TestCommandRouter_Serve(t *testing.T) {
+	router := NewCommandRouter()
+	var called string
+	var count uint64
+	router.Handle("consumer", func(command *Command, args Arguments) porterr.IError {
+		called = "consumer"
+		return nil
+	})
+	router.Handle("consumer stop", func(command *Command, args Arguments) porterr.IError {
+		called = "consumer stop"
+		count = args.GetByName("count").GetUnit()
+		if args.GetByName("name").GetString() != "orders" {
+			t.Fatal("wrong schema binding")
+		}
+		return nil
+	}, Argument{Name: "name", Type: ArgumentTypeString}, Argument{Name: "count", Type: ArgumentTypeUint})
+
+	t.Run("longest", func(t *testing.T) {
+		router.Serve(ParseCommand([]byte("consumer stop orders 10")))
+		if called != "consumer stop" || count != 10 {
+			t.Fatal("wrong route")
+		}
+		router.Serve(ParseCommand([]byte("consumer start")))
+		if called != "consumer" {
+			t.Fatal("wrong route")
+		}
+	})
+	t.Run("unknown", func(t *testing.T) {
+		command, r, closer := pipeCommand(t, "cache flush")
+		defer closer()
+		go router.Serve(command)
+		line, _, _ := r.ReadLine()
+		if !strings.Contains(string(line), "Unknown command: cache flush") {
+			t.Fatal("wrong unknown reply", string(line))
+		}
+	})
+	t.Run("schema", func(t *testing.T) {
+		called = ""
+		command, r, closer := pipeCommand(t, "consumer stop orders many")
+		defer closer()
+		go router.Serve(command)
+		line, _, _ := r.ReadLine()
+		if called != "" || !strings.Contains(string(line), "Command arguments are invalid") {
+			t.Fatal("schema must be checked", string(line))
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package gocli
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigSecretFilePrefix Prefix of secret file reference. Example: ${file:/run/secrets/db_password}
+	ConfigSecretFilePrefix = "file:"
+	// ConfigSecretMaxSize Max size of secret file in bytes
+	ConfigSecretMaxSize = 64 << 10
+	// ConfigSecretMask Replacement of secret values in config dump and messages
+	ConfigSecretMask = "***"
+)
+
+// readSecret read secret file with trimmed whitespaces
+func readSecret(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", errors.New(path + " is a directory")
+	}
+	if info.Size() > ConfigSecretMaxSize {
+		return "", errors.New(path + " exceeds " + strconv.Itoa(ConfigSecretMaxSize) + " bytes")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// registerSecret register secret value to mask it in config dump and messages
+func (a *DNApp) registerSecret(value string) {
+	for _, secret := range a.config.secrets {
+		if secret == value {
+			return
+		}
+	}
+	a.config.secrets = append(a.config.secrets, value)
+}
+
+// secretNode register scalar node with secret value and its masked value
+func (a *DNApp) secretNode(node *yaml.Node, masked string) {
+	if a.config.secretNodes == nil {
+		a.config.secretNodes = make(map[*yaml.Node]string)
+	}
+	a.config.secretNodes[node] = masked
+}
+
+// secretOnLine check if scalar node on line contains secret value
+func (a *DNApp) secretOnLine(node *yaml.Node, line int) bool {
+	if node == nil {
+		return false
+	}
+	if _, ok := a.config.secretNodes[node]; ok && node.Line == line {
+		return true
+	}
+	for _, child := range node.Content {
+		if a.secretOnLine(child, line) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecrets replace secret values in text with ConfigSecretMask
+func (a *DNApp) maskSecrets(text string) string {
+	a.configMutex.RLock()
+	defer a.configMutex.RUnlock()
+	return maskSecrets(text, a.config.secrets)
+}
+
+// maskSecrets replace secret values in text. Longer values are replaced first
+// Short secrets are masked in any word containing them. Example: secret "on" masks "london" as "lond***"
+func maskSecrets(text string, secrets []string) string {
+	if len(secrets) == 0 {
+		return text
+	}
+	values := make([]string, 0, len(secrets))
+	for _, value := range secrets {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, ConfigSecretMask)
+	}
+	return text
+}
+
+// maskNode copy of node with masked values of secret nodes
+func maskNode(node *yaml.Node, masks map[*yaml.Node]string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	clone := *node
+	if masked, ok := masks[node]; ok {
+		clone.Value = masked
+		if strings.HasPrefix(clone.Tag, "!!") {
+			clone.Tag, clone.Style = "!!str", 0
+		}
+	}
+	if node.Content != nil {
+		clone.Content = make([]*yaml.Node, len(node.Content))
+		for i := range node.Content {
+			clone.Content[i] = maskNode(node.Content[i], masks)
+		}
+	}
+	return &clone
+}
@@ -0,0 +1,137 @@
+package gocli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dimonrus/porterr"
+)
+
+func TestDNApp_secrets(t *testing.T) {
+	secrets := t.TempDir()
+	password := filepath.Join(secrets, "password")
+	if err := os.WriteFile(password, []byte("  p@ss: #word\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	large := filepath.Join(secrets, "large")
+	if err := os.WriteFile(large, make([]byte, ConfigSecretMaxSize+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var files = make(map[string]string)
+	for i := 0; i < 11; i++ {
+		files["many.env"] += "FEATURE_" + strings.Repeat("X", i) + "=${file:" + password + "}\n"
+	}
+	files["local.yaml"] = "project:\n  name: ${file:" + password + "}\nnote: db://user:${file:" + password + "}@host\nweb:\n  port: 80\n"
+	files["missing.json"] = "{\"note\": \"${file:" + filepath.Join(secrets, "missing") + "}\"}"
+	files["large.yaml"] = "note: ${file:" + large + "}\n"
+	files["type.yaml"] = "web:\n  port: ${file:" + password + "}\n"
+	port := filepath.Join(secrets, "port")
+	if err := os.WriteFile(port, []byte("8080\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	short := filepath.Join(secrets, "short")
+	if err := os.WriteFile(short, []byte("on"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	files["port.yaml"] = "web:\n  port: ${file:" + port + "}\n  host: '${file:" + port + "}'\n"
+	files["short.yaml"] = "project:\n  name: london\nnote: ${file:" + short + "}\n"
+	dir := writeTestConfigs(t, files)
+	t.Run("resolve", func(t *testing.T) {
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: dir}}
+		if e := app.ParseConfigE("local"); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Project.Name != "p@ss: #word" || cfg.Note != "db://user:p@ss: #word@host" {
+			t.Fatal("wrong secret", cfg.Project.Name, cfg.Note)
+		}
+		data, e := app.EffectiveConfig()
+		if e != nil {
+			t.Fatal(e)
+		}
+		if strings.Contains(string(data), "p@ss") || !strings.Contains(string(data), "db://user:***@host") {
+			t.Fatal("secret must be masked", string(data))
+		}
+		if app.maskSecrets("password is p@ss: #word") != "password is ***" {
+			t.Fatal("secret must be masked in messages")
+		}
+	})
+	t.Run("numeric", func(t *testing.T) {
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: dir}}
+		if e := app.ParseConfigE("port"); e != nil {
+			t.Fatal(e)
+		}
+		if cfg.Web.Port != 8080 || cfg.Web.Host != "8080" {
+			t.Fatal("plain secret must be typed, quoted must be string", cfg.Web)
+		}
+		data, _ := app.EffectiveConfig()
+		if strings.Contains(string(data), "8080") || strings.Count(string(data), ConfigSecretMask) != 2 {
+			t.Fatal("secret must be masked", string(data))
+		}
+	})
+	t.Run("short", func(t *testing.T) {
+		var cfg testConfig
+		app := &DNApp{config: config{values: &cfg, path: dir}}
+		if e := app.ParseConfigE("short"); e != nil {
+			t.Fatal(e)
+		}
+		data, _ := app.EffectiveConfig()
+		if !strings.Contains(string(data), "name: london") || !strings.Contains(string(data), "note: '***'") {
+			t.Fatal("only secret nodes must be masked", string(data))
+		}
+		if app.maskSecrets("switch is on") != "switch is ***" {
+			t.Fatal("short secret must be masked in messages")
+		}
+	})
+	t.Run("fatal_exit", func(t *testing.T) {
+		if os.Getenv("GOCLI_TEST_FATAL_EXIT") != "" {
+			var cfg testConfig
+			app, e := NewApplicationE("local", dir, &cfg)
+			if e != nil {
+				t.Fatal(e)
+			}
+			e = porterr.New(porterr.PortErrorIO, "can't connect with p@ss: #word")
+			e = e.PushDetail(porterr.PortErrorArgument, "password", "p@ss: #word is rejected")
+			FatalExit(3)(app, e)
+			return
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=TestDNApp_secrets/fatal_exit")
+		cmd.Env = append(os.Environ(), "GOCLI_TEST_FATAL_EXIT=1")
+		output, err := cmd.CombinedOutput()
+		if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 3 {
+			t.Fatal("must exit with code 3", err, string(output))
+		}
+		if strings.Contains(string(output), "p@ss") || !strings.Contains(string(output), "can't connect with ***") || !strings.Contains(string(output), "password: *** is rejected") {
+			t.Fatal("secrets must be masked in fatal error", string(output))
+		}
+	})
+	t.Run("many", func(t *testing.T) {
+		var cfg map[string]string
+		app := &DNApp{config: config{values: &cfg, path: dir}}
+		if e := app.ParseConfigE("many"); e != nil {
+			t.Fatal(e)
+		}
+		if len(cfg) != 11 || cfg["feature_xxxxxxxxxx"] != "p@ss: #word" {
+			t.Fatal("wrong secrets", cfg)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		app := &DNApp{config: config{values: &testConfig{}, path: dir}}
+		e := app.ParseConfigE("missing")
+		if e == nil || len(e.GetDetails()) != 1 || !strings.HasSuffix(e.GetDetails()[0].Origin().Name, "missing") {
+			t.Fatal("must be missing secret error", e)
+		}
+		e = app.ParseConfigE("large")
+		if e == nil || !strings.Contains(e.GetDetails()[0].Error(), "exceeds") {
+			t.Fatal("must be size error", e)
+		}
+		e = app.ParseConfigE("type")
+		if e == nil || strings.Contains(e.Error(), "p@ss") || !strings.Contains(e.Error(), ConfigSecretMask) {
+			t.Fatal("secret must be masked in error", e)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package gocli
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dimonrus/porterr"
+)
+
+// session command connection state
+type session struct {
+	// net connection
+	conn net.Conn
+	// command callback in progress
+	busy bool
+}
+
+// sessionPool registry of opened command sessions
+type sessionPool struct {
+	// active sessions
+	sessions map[*session]struct{}
+	// pool is in shutdown state
+	closing bool
+	// wait group for all sessions
+	wg sync.WaitGroup
+	// mutex for async access
+	m sync.Mutex
+}
+
+// newSessionPool init session pool
+func newSessionPool() *sessionPool {
+	return &sessionPool{sessions: make(map[*session]struct{})}
+}
+
+// add register connection in pool. Returns nil if pool is closing
+func (p *sessionPool) add(conn net.Conn) *session {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.closing {
+		return nil
+	}
+	s := &session{conn: conn}
+	p.sessions[s] = struct{}{}
+	p.wg.Add(1)
+	return s
+}
+
+// remove unregister session
+func (p *sessionPool) remove(s *session) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if _, ok := p.sessions[s]; ok {
+		delete(p.sessions, s)
+		p.wg.Done()
+	}
+}
+
+// acquire mark session as busy. Returns false when pool is closing
+func (p *sessionPool) acquire(s *session) bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.closing {
+		return false
+	}
+	s.busy = true
+	return true
+}
+
+// release mark session as idle. Returns false when pool is closing
+func (p *sessionPool) release(s *session) bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	s.busy = false
+	return !p.closing
+}
+
+// isClosing check if pool in shutdown state
+func (p *sessionPool) isClosing() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.closing
+}
+
+// shutdown stop accepting commands and close idle sessions
+func (p *sessionPool) shutdown() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.closing = true
+	for s := range p.sessions {
+		if !s.busy {
+			_ = s.conn.Close()
+		}
+	}
+}
+
+// drain wait for busy sessions. Force close all sessions after timeout
+func (p *sessionPool) drain(timeout time.Duration) porterr.IError {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+	}
+	p.m.Lock()
+	for s := range p.sessions {
+		_ = s.conn.Close()
+	}
+	p.m.Unlock()
+	return porterr.NewF(porterr.PortErrorIO, "Drain timeout %s exceeded", timeout)
+}
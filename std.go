@@ -2,16 +2,20 @@ package gocli
 
 import (
 	"bufio"
-	"errors"
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
-	"testing"
+	"sync"
+	"time"
 
 	"github.com/dimonrus/gohelp"
 	"github.com/dimonrus/porterr"
@@ -22,13 +26,19 @@ const (
 	CommandSessionHost = "localhost"
 	CommandSessionPort = "8080"
 	CommandSessionType = "tcp"
+
+	// CommandSessionDrainTimeout Default time to wait in-flight commands on shutdown
+	CommandSessionDrainTimeout = time.Second * 5
 )
 
 var (
 	// Depends config files
+	// Deprecated: depends is parsed as yaml key. See ConfigKeyDepends
 	RegExpDepends, _ = regexp.Compile(`depends:(.*)`)
-	// ENV variables in config
-	RegExpENV, _ = regexp.Compile(`\$\{(.*)\}`)
+	// ENV variables in config. Matches ${VAR}, ${VAR:-default} and ${VAR:?message}
+	RegExpENV, _ = regexp.Compile(`\$\{([^{}]*)\}`)
+	// ENV variables or escaped dollar in config
+	regExpExpand = regexp.MustCompile(`\$\$|\$\{([^{}]*)\}`)
 )
 
 // DNApp Dynamic Name Application
@@ -38,41 +48,183 @@ type DNApp struct {
 	config config
 	// Logger
 	logger Logger
+	// Time to wait in-flight commands on shutdown
+	drainTimeout time.Duration
+	// Last parsed flag set
+	flags *flag.FlagSet
+	// Flag parse error handling mode. Zero value is flag.ContinueOnError
+	flagErrorHandling flag.ErrorHandling
+	// Arguments parsed without mode
+	arguments ArgumentMap
+	// Registered application modes
+	modes []Mode
+	// Usage output
+	output io.Writer
+	// Usage format
+	usageFormat string
+	// Prefix of environment variables bound to arguments
+	envPrefix string
+	// Merge strategies by config key path
+	mergeStrategies map[string]string
+	// Handler of fatal errors
+	fatalHandler FatalHandler
+	// Registered config decoders by extension
+	configDecoders map[string]ConfigDecoder
+	// Registered config extensions in lookup order
+	configExtensions []string
+	// Interval of config files polling
+	watchInterval time.Duration
+	// Guard config swap on reload
+	configMutex sync.RWMutex
 }
 
 // Application configuration
 type config struct {
 	// Values of parsed configs
 	values interface{}
-	// Path of config
+	// Path of config. Directory in fsys if config is loaded from file system
 	path string
+	// File system with config files. Nil for disk
+	fsys fs.FS
+	// Directory on disk with configs merged over configs in fsys
+	override string
+	// Merged config node
+	node *yaml.Node
+	// Report config keys not defined in config struct
+	strict bool
+	// Parsed env
+	env string
+	// Env config file, files of depends chain and secret files
+	files []string
+	// Secret values masked in messages
+	secrets []string
+	// Scalar nodes with secret values and masked values. Used while config is parsed
+	secretNodes map[*yaml.Node]string
+	// Merged config with masked secret values
+	masked *yaml.Node
+	// Environment variable with encryption key
+	keyEnv string
+	// File with encryption key
+	keyFile string
+}
+
+// ApplicationOption Option of application applied before config is parsed
+// Example: gocli.NewApplication(env, path, &config, gocli.WithMergeStrategy("web.hosts", gocli.MergeStrategyAppend))
+type ApplicationOption func(app *DNApp)
+
+// NewApplication Create new Application. Config errors are passed to FatalError
+func NewApplication(env string, configPath string, values interface{}, options ...ApplicationOption) Application {
+	app, e := NewApplicationE(env, configPath, values, options...)
+	if e != nil {
+		app.FatalError(e)
+	}
+	return app
+}
+
+// NewApplicationE Create new application and return config errors
+func NewApplicationE(env string, configPath string, values interface{}, options ...ApplicationOption) (Application, porterr.IError) {
+	app := newApplication(nil, configPath, values, options)
+	return app, app.ParseConfigE(env)
+}
+
+// NewApplicationFS Create new application with configs from file system. Example: embed.FS
+// Configs in optional override directory on disk are merged over configs of same env in file system. Config errors are passed to FatalError
+func NewApplicationFS(env string, fsys fs.FS, dir string, values interface{}, override ...string) Application {
+	var options []ApplicationOption
+	if len(override) > 0 {
+		options = append(options, WithConfigOverride(override[0]))
+	}
+	app, e := NewApplicationFSE(env, fsys, dir, values, options...)
+	if e != nil {
+		app.FatalError(e)
+	}
+	return app
+}
+
+// NewApplicationFSE Create new application with configs from file system and return config errors
+func NewApplicationFSE(env string, fsys fs.FS, dir string, values interface{}, options ...ApplicationOption) (Application, porterr.IError) {
+	app := newApplication(fsys, dir, values, options)
+	return app, app.ParseConfigE(env)
 }
 
-// NewApplication Create new Application
-func NewApplication(env string, configPath string, values interface{}) Application {
+// WithConfigOverride Merge configs in directory on disk over configs of same env in file system
+// Example: gocli.NewApplicationFSE(env, configFS, "config/yaml", &config, gocli.WithConfigOverride("/etc/myservice"))
+func WithConfigOverride(dir string) ApplicationOption {
+	return func(app *DNApp) {
+		app.config.override = dir
+	}
+}
+
+// newApplication create application with config source and apply options. Configs are read from disk if fsys is nil
+func newApplication(fsys fs.FS, path string, values interface{}, options []ApplicationOption) *DNApp {
 	app := &DNApp{
 		config: config{
 			values: values,
-			path:   configPath,
+			path:   path,
+			fsys:   fsys,
 		},
 	}
-	return app.ParseConfig(env)
+	for _, option := range options {
+		option(app)
+	}
+	return app
 }
 
 // GetConfig Get config struct
 func (a *DNApp) GetConfig() interface{} {
+	a.configMutex.RLock()
+	defer a.configMutex.RUnlock()
 	return a.config.values
 }
 
 // SetConfig Set config struct
 func (a *DNApp) SetConfig(cfg interface{}) Application {
+	a.configMutex.Lock()
 	a.config.values = cfg
+	a.configMutex.Unlock()
 	return a
 }
 
-// GetConfigPath Get full path to config
+// GetConfigPath Get full path to config. Path is relative to file system root if config is loaded from fs.FS
+// First existing file with supported extension is used. Yaml path is returned if no config exists
 func (a *DNApp) GetConfigPath(env string) string {
-	return fmt.Sprintf("%s/%s.yaml", a.config.path, env)
+	if path, ok := a.findConfig(a.config.fsys, a.config.path, env); ok {
+		return path
+	}
+	return configFile(a.config.fsys, a.config.path, env+ConfigExtensionYaml)
+}
+
+// getOverridePath Get path to config in override directory if exists
+func (a *DNApp) getOverridePath(env string) (string, bool) {
+	if a.config.override == "" {
+		return "", false
+	}
+	return a.findConfig(nil, a.config.override, env)
+}
+
+// findConfig find env config with supported extension in dir of file system or on disk if fsys is nil
+func (a *DNApp) findConfig(fsys fs.FS, dir string, env string) (string, bool) {
+	for _, extension := range a.getConfigExtensions() {
+		path := configFile(fsys, dir, env+extension)
+		var err error
+		if fsys == nil {
+			_, err = os.Stat(path)
+		} else {
+			_, err = fs.Stat(fsys, path)
+		}
+		if err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// configFile path of config file in dir of file system or on disk if fsys is nil
+func configFile(fsys fs.FS, dir string, name string) string {
+	if fsys == nil {
+		return fmt.Sprintf("%s/%s", dir, name)
+	}
+	return path.Join(dir, name)
 }
 
 // GetAbsolutePath Get absolute path to application
@@ -87,11 +239,45 @@ func (a *DNApp) GetAbsolutePath(path string, dir string) (string, porterr.IError
 	return gohelp.BeforeString(rootPath, dir) + dir + string(os.PathSeparator) + path, nil
 }
 
-// FatalError Fatal error
+// FatalError Fatal error. Handled by fatal handler, panics by default
 func (a *DNApp) FatalError(err error) {
+	if a.fatalHandler == nil {
+		FatalPanic(a, err)
+		return
+	}
+	a.fatalHandler(a, err)
+}
+
+// SetFatalHandler Set fatal error handler. Example: app.SetFatalHandler(gocli.FatalExit(1))
+func (a *DNApp) SetFatalHandler(handler FatalHandler) {
+	a.fatalHandler = handler
+}
+
+// FatalHandler Handler of fatal errors
+type FatalHandler func(app Application, err error)
+
+// FatalPanic Panic on fatal error. Default fatal handler
+func FatalPanic(app Application, err error) {
 	panic(err)
 }
 
+// FatalExit Log fatal error with details and exit with code. Config secrets are masked
+func FatalExit(code int) FatalHandler {
+	return func(app Application, err error) {
+		mask := func(text string) string { return text }
+		if masker, ok := app.(interface{ maskSecrets(text string) string }); ok {
+			mask = masker.maskSecrets
+		}
+		app.GetLogger().Errorln(mask(err.Error()))
+		if e, ok := err.(porterr.IError); ok {
+			for _, detail := range e.GetDetails() {
+				app.GetLogger().Errorln(mask(detail.Origin().Name + ": " + detail.Error()))
+			}
+		}
+		os.Exit(code)
+	}
+}
+
 // GetLogger Get logger
 func (a *DNApp) GetLogger() Logger {
 	if a.logger == nil {
@@ -106,9 +292,22 @@ func (a *DNApp) SetLogger(logger Logger) {
 	return
 }
 
+// GetDrainTimeout Get time to wait in-flight commands on shutdown
+func (a *DNApp) GetDrainTimeout() time.Duration {
+	if a.drainTimeout <= 0 {
+		return CommandSessionDrainTimeout
+	}
+	return a.drainTimeout
+}
+
+// SetDrainTimeout Set time to wait in-flight commands on shutdown
+func (a *DNApp) SetDrainTimeout(timeout time.Duration) {
+	a.drainTimeout = timeout
+}
+
 // SuccessMessage printing success message
 func (a *DNApp) SuccessMessage(message string, command ...*Command) {
-	message = gohelp.AnsiGreen + message + gohelp.AnsiReset
+	message = gohelp.AnsiGreen + a.maskSecrets(message) + gohelp.AnsiReset
 	_ = a.GetLogger().Output(DefaultCallDepth, message)
 	for _, c := range command {
 		e := c.Result([]byte(message + "\n"))
@@ -121,7 +320,7 @@ func (a *DNApp) SuccessMessage(message string, command ...*Command) {
 
 // AttentionMessage printing attention message
 func (a *DNApp) AttentionMessage(message string, command ...*Command) {
-	message = gohelp.AnsiCyan + message + gohelp.AnsiReset
+	message = gohelp.AnsiCyan + a.maskSecrets(message) + gohelp.AnsiReset
 	_ = a.GetLogger().Output(DefaultCallDepth, message)
 	for _, c := range command {
 		e := c.Result([]byte(message + "\n"))
@@ -134,7 +333,7 @@ func (a *DNApp) AttentionMessage(message string, command ...*Command) {
 
 // FailMessage printing fail message
 func (a *DNApp) FailMessage(message string, command ...*Command) {
-	message = gohelp.AnsiRed + message + gohelp.AnsiReset
+	message = gohelp.AnsiRed + a.maskSecrets(message) + gohelp.AnsiReset
 	_ = a.GetLogger().Output(DefaultCallDepth, message)
 	for _, c := range command {
 		e := c.Result([]byte(message + "\n"))
@@ -147,74 +346,68 @@ func (a *DNApp) FailMessage(message string, command ...*Command) {
 
 // ParseConfig parse config depends on env
 func (a *DNApp) ParseConfig(env string) Application {
-	data, err := os.ReadFile(a.GetConfigPath(env))
-	if err != nil {
-		a.FatalError(err)
-	}
-	content := string(data)
-	// check if config has depends on other configs
-	matches := RegExpDepends.FindStringSubmatch(content)
-	if len(matches) > 1 && strings.TrimSpace(matches[1]) != "" {
-		// load parent config
-		a.ParseConfig(strings.TrimSpace(matches[1]))
-	}
-	envMatches := RegExpENV.FindAllStringSubmatch(content, -1)
-	for _, m := range envMatches {
-		v, ok := os.LookupEnv(m[1])
-		if ok {
-			content = strings.ReplaceAll(content, m[0], v)
-		} else {
-			a.FailMessage("Environment: " + m[1] + " is not defined")
-		}
-	}
-	// unmarshal config file in config struct
-	err = yaml.Unmarshal([]byte(content), a.config.values)
-	if err != nil {
-		a.FatalError(err)
+	e := a.ParseConfigE(env)
+	if e != nil {
+		a.FatalError(e)
 	}
 	return a
 }
 
 // ParseFlags parse console arguments
-func (a *DNApp) ParseFlags(args ArgumentMap) {
-	for key, argument := range args {
-		argument.Name = key
-		switch argument.Type {
-		case ArgumentTypeString:
-			var value string
-			argument.Value = &value
-			args[key] = argument
-			flag.StringVar(&value, key, "", argument.Label)
-		case ArgumentTypeInt:
-			var value int64
-			argument.Value = &value
-			args[key] = argument
-			flag.Int64Var(&value, key, 0, argument.Label)
-		case ArgumentTypeUint:
-			var value uint64
-			argument.Value = &value
-			args[key] = argument
-			flag.Uint64Var(&value, key, 0, argument.Label)
-		case ArgumentTypeFloat:
-			var value float64
-			argument.Value = &value
-			args[key] = argument
-			flag.Float64Var(&value, key, 0, argument.Label)
-		case ArgumentTypeBool:
-			var value bool
-			argument.Value = &value
-			args[key] = argument
-			flag.BoolVar(&value, key, false, argument.Label)
-		default:
-			a.FatalError(errors.New("argument type: " + argument.Type + " is not supported. Argument: " + argument.Label))
-		}
+// Env and default values are applied to arguments not passed in command line
+// Unsupported argument types, parse and validation errors are returned. Pass error to FatalError to stop application
+func (a *DNApp) ParseFlags(args ArgumentMap) porterr.IError {
+	return a.ParseFlagsFrom(os.Args[1:], args)
+}
+
+// ParseFlagsFrom parse arguments from list in dedicated flag set
+// Can be called many times. Parse errors are handled according to flag error handling mode
+func (a *DNApp) ParseFlagsFrom(args []string, argMap ArgumentMap) porterr.IError {
+	a.arguments = argMap
+	return a.parseFlags(UsageGroup{Arguments: argMap}, args)
+}
+
+// parseFlags parse group arguments in dedicated flag set. Usage of group is printed on -h
+func (a *DNApp) parseFlags(group UsageGroup, args []string) porterr.IError {
+	name := filepath.Base(os.Args[0])
+	set := flag.NewFlagSet(name, a.flagErrorHandling)
+	set.SetOutput(a.GetOutput())
+	e := registerArguments(set, group.Arguments, a.envPrefix)
+	if e != nil {
+		return e
+	}
+	if group.Name != "" {
+		name += " " + group.Name
 	}
-	testing.Init()
-	flag.Parse()
+	set.Usage = func() {
+		RenderUsage(set.Output(), a.GetUsageFormat(), name+" [arguments]", group)
+	}
+	a.flags = set
+	if err := set.Parse(args); err != nil {
+		return porterr.New(porterr.PortErrorArgument, err.Error())
+	}
+	return bindArguments(set, group.Arguments)
+}
+
+// SetEnvPrefix Bind arguments without env key to prefixed environment variables. Example: DNA_COUNT
+func (a *DNApp) SetEnvPrefix(prefix string) {
+	a.envPrefix = prefix
+}
+
+// SetFlagErrorHandling Set flag parse error handling mode. Default is flag.ContinueOnError
+// Unknown flags such as -test.* flags of go test are returned as parse error
+func (a *DNApp) SetFlagErrorHandling(handling flag.ErrorHandling) {
+	a.flagErrorHandling = handling
 }
 
 // Start run application
 func (a *DNApp) Start(address string, callback func(command *Command)) porterr.IError {
+	return a.StartContext(context.Background(), address, callback)
+}
+
+// StartContext run application until context is done
+// On context cancel listener stops accepting, in-flight commands are drained within drain timeout
+func (a *DNApp) StartContext(ctx context.Context, address string, callback func(command *Command)) porterr.IError {
 	if address == "" {
 		return porterr.NewF(porterr.PortErrorArgument, "address is required")
 	}
@@ -236,10 +429,25 @@ func (a *DNApp) Start(address string, callback func(command *Command)) porterr.I
 	if err != nil {
 		return porterr.NewF(porterr.PortErrorIO, "Listen socket error: %s", err.Error())
 	}
-	defer func() {
-		err := l.Close()
-		if err != nil {
-			a.GetLogger().Errorln(err)
+	var once sync.Once
+	closeListener := func() {
+		once.Do(func() {
+			err := l.Close()
+			if err != nil {
+				a.GetLogger().Errorln(err)
+			}
+		})
+	}
+	defer closeListener()
+	pool := newSessionPool()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			pool.shutdown()
+			closeListener()
+		case <-done:
 		}
 	}()
 	a.GetLogger().Infof("Start listening %s commands on %s:%s", CommandSessionType, CommandSessionHost, port)
@@ -248,46 +456,76 @@ func (a *DNApp) Start(address string, callback func(command *Command)) porterr.I
 		// Listen for an incoming connection.
 		conn, err := l.Accept()
 		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
 			e = porterr.NewF(porterr.PortErrorIO, "Accept socket error: %s", err.Error())
 			break
 		}
+		s := pool.add(conn)
+		if s == nil {
+			_ = conn.Close()
+			continue
+		}
 		// Handle command
-		go func(c net.Conn) {
-			defer func() {
-				if err := recover(); err != nil {
-					a.GetLogger().Errorln("Command processor error:", err)
-				}
-				// Always close the connection after process command
-				err = c.Close()
-				if err != nil {
-					a.GetLogger().Errorln(err)
-					return
-				}
-			}()
-			r := bufio.NewReader(c)
-			for {
-				com, _, err := r.ReadLine()
-				if err != nil {
-					if err == io.EOF {
-						a.GetLogger().Errorln(gohelp.AnsiYellow + "Client connection closed" + gohelp.AnsiReset)
-					} else {
-						a.GetLogger().Errorln(gohelp.AnsiRed + err.Error() + gohelp.AnsiReset)
-					}
-					break
-				}
-				commands := strings.Split(string(com), CommandDelimiter)
-				for _, comm := range commands {
-					comm = strings.Trim(comm, " 	")
-					// Parse command and run
-					if comm == "" {
-						continue
-					}
-					command := ParseCommand([]byte(comm))
-					command.BindConnection(c)
-					callback(command)
-				}
-			}
-		}(conn)
+		go a.serveSession(pool, s, callback)
+	}
+	if ctx.Err() != nil {
+		e = pool.drain(a.GetDrainTimeout())
+		if e == nil {
+			a.GetLogger().Infof("Stop listening %s commands on %s:%s", CommandSessionType, CommandSessionHost, port)
+		}
 	}
 	return e
 }
+
+// serveSession read and process commands from session connection
+func (a *DNApp) serveSession(pool *sessionPool, s *session, callback func(command *Command)) {
+	defer func() {
+		if err := recover(); err != nil {
+			a.GetLogger().Errorln("Command processor error:", err)
+		}
+		// Always close the connection after process command
+		err := s.conn.Close()
+		if err != nil && !pool.isClosing() {
+			a.GetLogger().Errorln(err)
+		}
+		pool.remove(s)
+	}()
+	r := bufio.NewReader(s.conn)
+	for {
+		com, _, err := r.ReadLine()
+		if err != nil {
+			if pool.isClosing() {
+				break
+			}
+			if err == io.EOF {
+				a.GetLogger().Errorln(gohelp.AnsiYellow + "Client connection closed" + gohelp.AnsiReset)
+			} else {
+				a.GetLogger().Errorln(gohelp.AnsiRed + err.Error() + gohelp.AnsiReset)
+			}
+			break
+		}
+		if !pool.acquire(s) {
+			break
+		}
+		commands := splitCommands(com)
+		for _, comm := range commands {
+			comm = bytes.Trim(comm, " 	")
+			// Parse command and run
+			if len(comm) == 0 {
+				continue
+			}
+			command, e := ParseCommandE(comm)
+			command.BindConnection(s.conn)
+			if e != nil {
+				a.FailMessage(e.Error(), command)
+				continue
+			}
+			callback(command)
+		}
+		if !pool.release(s) {
+			break
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package gocli
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimonrus/porterr"
+)
+
+func dialCommandSession(t *testing.T, address string) net.Conn {
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial(CommandSessionType, address)
+		if err == nil {
+			return conn
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	t.Fatal("can't connect to " + address)
+	return nil
+}
+
+func TestDNApp_StartContext(t *testing.T) {
+	t.Run("in_flight", func(t *testing.T) {
+		app := &DNApp{}
+		ctx, cancel := context.WithCancel(context.Background())
+		var processed int32
+		started := make(chan struct{})
+		result := make(chan error)
+		go func() {
+			result <- app.StartContext(ctx, "127.0.0.1:33401", func(command *Command) {
+				close(started)
+				time.Sleep(time.Millisecond * 200)
+				atomic.AddInt32(&processed, 1)
+			})
+		}()
+		conn := dialCommandSession(t, "127.0.0.1:33401")
+		defer conn.Close()
+		idle := dialCommandSession(t, "127.0.0.1:33401")
+		defer idle.Close()
+		_, _ = conn.Write([]byte("slow\n"))
+		<-started
+		cancel()
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatal("server is not stopped")
+		}
+		if atomic.LoadInt32(&processed) != 1 {
+			t.Fatal("in-flight command must be finished")
+		}
+	})
+	t.Run("drain_timeout", func(t *testing.T) {
+		app := &DNApp{}
+		app.SetDrainTimeout(time.Millisecond * 50)
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		result := make(chan error)
+		go func() {
+			result <- app.StartContext(ctx, "127.0.0.1:33402", func(command *Command) {
+				close(started)
+				time.Sleep(time.Second)
+			})
+		}()
+		conn := dialCommandSession(t, "127.0.0.1:33402")
+		defer conn.Close()
+		_, _ = conn.Write([]byte("hang\n"))
+		<-started
+		cancel()
+		select {
+		case err := <-result:
+			if err == nil {
+				t.Fatal("must be drain timeout error")
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatal("server is not stopped")
+		}
+	})
+	t.Run("router", func(t *testing.T) {
+		app := &DNApp{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		router := NewCommandRouter()
+		router.Handle("ping", func(command *Command, args Arguments) porterr.IError {
+			app.SuccessMessage("pong", command)
+			return nil
+		})
+		go func() {
+			_ = app.StartContext(ctx, "127.0.0.1:33403", router.Serve)
+		}()
+		conn := dialCommandSession(t, "127.0.0.1:33403")
+		defer conn.Close()
+		_, _ = conn.Write([]byte("ping\n"))
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second * 2))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil || !strings.Contains(line, "pong") {
+			t.Fatal("router must reply", line, err)
+		}
+	})
+}
+
+func TestDNApp_ParseFlagsFrom(t *testing.T) {
+	app := &DNApp{}
+	for i := 0; i < 2; i++ {
+		args := ArgumentMap{
+			"count": {Type: ArgumentTypeInt, Label: "count"},
+			"name":  {Type: ArgumentTypeString, Label: "name"},
+		}
+		e := app.ParseFlagsFrom([]string{"-count=5", "-name", "orders"}, args)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if args["count"].IntOr(0) != 5 || args["name"].StringOr("") != "orders" {
+			t.Fatal("wrong parse")
+		}
+	}
+	e := app.ParseFlagsFrom([]string{"-unknown=1"}, ArgumentMap{"count": {Type: ArgumentTypeInt}})
+	if e == nil {
+		t.Fatal("must be parse error")
+	}
+	e = app.ParseFlagsFrom(nil, ArgumentMap{"count": {Type: "complex"}})
+	if e == nil {
+		t.Fatal("unsupported type must be returned as error")
+	}
+}
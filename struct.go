@@ -0,0 +1,234 @@
+package gocli
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dimonrus/porterr"
+)
+
+const (
+	// TagArgument Struct tag with argument name and options. Example: cli:"count,required"
+	TagArgument = "cli"
+	// TagEnv Struct tag with environment variable name
+	TagEnv = "env"
+	// TagDefault Struct tag with default value
+	TagDefault = "default"
+	// TagUsage Struct tag with argument label
+	TagUsage = "usage"
+	// TagEnum Struct tag with comma separated enum values
+	TagEnum = "enum"
+	// TagType Struct tag to override argument type. Example: type:"path"
+	TagType = "type"
+	// TagPattern Struct tag with regular expression for value
+	TagPattern = "pattern"
+	// TagMin Struct tag with min value, length or items count
+	TagMin = "min"
+	// TagMax Struct tag with max value, length or items count
+	TagMax = "max"
+
+	// StructPrefixDelimiter Delimiter between nested struct prefix and argument name
+	StructPrefixDelimiter = "-"
+)
+
+// StructArguments Create argument map from struct fields
+// Nested structs are prefixed with field name. Non-zero field values are used as defaults
+func StructArguments(v interface{}) (ArgumentMap, porterr.IError) {
+	args := make(ArgumentMap)
+	e := walkStruct(v, func(name string, field reflect.Value, tag reflect.StructTag) porterr.IError {
+		argument, e := fieldArgument(name, field, tag)
+		if e != nil {
+			return e
+		}
+		if _, ok := args[name]; ok {
+			return porterr.New(porterr.PortErrorArgument, "argument "+name+" is duplicated")
+		}
+		args[name] = argument
+		return nil
+	})
+	return args, e
+}
+
+// BindStruct Set parsed argument values to struct fields. Fields of arguments without value are not changed
+func BindStruct(v interface{}, args ArgumentMap) porterr.IError {
+	return walkStruct(v, func(name string, field reflect.Value, tag reflect.StructTag) porterr.IError {
+		argument, ok := args[name]
+		if !ok || argument.Source() == ArgumentSourceZero {
+			return nil
+		}
+		argument.Name = name
+		return setValue(field, argument)
+	})
+}
+
+// ParseFlagsInto parse command line into struct fields tagged with cli, env, default and usage
+// Argument maps are parsed in same flag set and receive their values too
+func (a *DNApp) ParseFlagsInto(v interface{}, argMap ...ArgumentMap) porterr.IError {
+	return a.ParseFlagsIntoFrom(os.Args[1:], v, argMap...)
+}
+
+// ParseFlagsIntoFrom parse argument list into struct fields and argument maps
+func (a *DNApp) ParseFlagsIntoFrom(args []string, v interface{}, argMap ...ArgumentMap) porterr.IError {
+	arguments, e := StructArguments(v)
+	if e != nil {
+		return e
+	}
+	for _, m := range argMap {
+		for key, argument := range m {
+			if _, ok := arguments[key]; ok {
+				return porterr.New(porterr.PortErrorArgument, "argument "+key+" is duplicated")
+			}
+			arguments[key] = argument
+		}
+	}
+	e = a.ParseFlagsFrom(args, arguments)
+	for _, m := range argMap {
+		for key := range m {
+			m[key] = arguments[key]
+		}
+	}
+	if e != nil {
+		return e
+	}
+	return BindStruct(v, arguments)
+}
+
+// walkStruct call callback for each argument field of struct pointer
+func walkStruct(v interface{}, callback func(name string, field reflect.Value, tag reflect.StructTag) porterr.IError) porterr.IError {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return porterr.New(porterr.PortErrorArgument, "pointer to struct is required")
+	}
+	return walkStructValue(rv.Elem(), "", callback)
+}
+
+// walkStructValue walk struct fields with name prefix
+func walkStructValue(rv reflect.Value, prefix string, callback func(name string, field reflect.Value, tag reflect.StructTag) porterr.IError) porterr.IError {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get(TagArgument), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = kebabCase(sf.Name)
+		}
+		field := rv.Field(i)
+		if sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			nested := prefix + name + StructPrefixDelimiter
+			if sf.Anonymous && sf.Tag.Get(TagArgument) == "" {
+				nested = prefix
+			}
+			if e := walkStructValue(field, nested, callback); e != nil {
+				return e
+			}
+			continue
+		}
+		if e := callback(prefix+name, field, sf.Tag); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// fieldArgument create argument for struct field
+func fieldArgument(name string, field reflect.Value, tag reflect.StructTag) (Argument, porterr.IError) {
+	argument := Argument{
+		Name:    name,
+		Label:   tag.Get(TagUsage),
+		Env:     tag.Get(TagEnv),
+		Pattern: tag.Get(TagPattern),
+	}
+	_, options, _ := strings.Cut(tag.Get(TagArgument), ",")
+	for _, option := range strings.Split(options, ",") {
+		if option == "required" {
+			argument.Required = true
+		}
+	}
+	if enum := tag.Get(TagEnum); enum != "" {
+		argument.Enum = splitList(enum)
+	}
+	for key, target := range map[string]**float64{TagMin: &argument.Min, TagMax: &argument.Max} {
+		if limit := tag.Get(key); limit != "" {
+			value, err := strconv.ParseFloat(limit, 64)
+			if err != nil {
+				return argument, porterr.New(porterr.PortErrorArgument, "argument "+name+": "+key+" is not a number")
+			}
+			*target = &value
+		}
+	}
+	argument.Type = tag.Get(TagType)
+	if argument.Type == "" {
+		argument.Type = fieldType(field.Type())
+		if argument.Type == ArgumentTypeString && len(argument.Enum) > 0 {
+			argument.Type = ArgumentTypeEnum
+		}
+	}
+	if argument.Type == "" {
+		return argument, porterr.New(porterr.PortErrorArgument, "argument "+name+": type "+field.Type().String()+" is not supported")
+	}
+	if def, ok := tag.Lookup(TagDefault); ok {
+		argument.Default = def
+	} else if !field.IsZero() {
+		argument.Default = field.Interface()
+	}
+	return argument, nil
+}
+
+// fieldType argument type for struct field type
+func fieldType(t reflect.Type) string {
+	switch t {
+	case durationType:
+		return ArgumentTypeDuration
+	case timeType:
+		return ArgumentTypeTime
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return ArgumentTypeString
+	case reflect.Bool:
+		return ArgumentTypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ArgumentTypeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ArgumentTypeUint
+	case reflect.Float32, reflect.Float64:
+		return ArgumentTypeFloat
+	case reflect.Slice:
+		switch t.Elem().Kind() {
+		case reflect.String:
+			return ArgumentTypeStringSlice
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return ArgumentTypeIntSlice
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return ArgumentTypeMap
+		}
+	}
+	return ""
+}
+
+// kebabCase convert field name to argument name. Example: MaxCount -> max-count, DBHost -> db-host
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var result []rune
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				result = append(result, '-')
+			}
+			r = unicode.ToLower(r)
+		}
+		result = append(result, r)
+	}
+	return string(result)
+}
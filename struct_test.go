@@ -0,0 +1,88 @@
+package gocli
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+type testDatabaseOptions struct {
+	Host string `usage:"database host" default:"localhost"`
+	Port uint16 `cli:"port" default:"5432" max:"65535"`
+}
+
+type testOptions struct {
+	Count    int               `cli:"count,required" env:"GOCLI_TEST_COUNT" usage:"items count"`
+	Name     string            `usage:"consumer name"`
+	Mode     string            `enum:"sync,async" default:"sync"`
+	Timeout  time.Duration     `default:"5s"`
+	Tags     []string          `cli:"tag"`
+	IDs      []int32           `cli:"ids"`
+	Labels   map[string]string `cli:"labels"`
+	Verbose  bool
+	MaxRetry int
+	Ignored  string              `cli:"-"`
+	Database testDatabaseOptions `cli:"db"`
+}
+
+func TestDNApp_ParseFlagsInto(t *testing.T) {
+	t.Run("bind", func(t *testing.T) {
+		_ = os.Setenv("GOCLI_TEST_COUNT", "7")
+		defer os.Unsetenv("GOCLI_TEST_COUNT")
+		opts := testOptions{MaxRetry: 3}
+		args := ArgumentMap{"app": {Type: ArgumentTypeString}}
+		app := &DNApp{}
+		e := app.ParseFlagsIntoFrom([]string{
+			"-name=orders", "-tag=a,b", "-ids=1,2", "-labels=env=prod", "-verbose", "-db-port=6432", "-app=script",
+		}, &opts, args)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if opts.Count != 7 || opts.Name != "orders" || opts.Mode != "sync" || opts.Timeout != time.Second*5 {
+			t.Fatal("wrong scalar binding", opts)
+		}
+		if len(opts.Tags) != 2 || len(opts.IDs) != 2 || opts.IDs[1] != 2 || opts.Labels["env"] != "prod" || !opts.Verbose {
+			t.Fatal("wrong list binding", opts)
+		}
+		if opts.MaxRetry != 3 {
+			t.Fatal("field value must be kept as default")
+		}
+		if opts.Database.Host != "localhost" || opts.Database.Port != 6432 {
+			t.Fatal("wrong nested binding", opts.Database)
+		}
+		if args["app"].StringOr("") != "script" {
+			t.Fatal("argument map must be parsed in same flag set")
+		}
+	})
+	t.Run("validation", func(t *testing.T) {
+		var opts testOptions
+		app := &DNApp{}
+		e := app.ParseFlagsIntoFrom([]string{"-mode=batch"}, &opts)
+		if e == nil {
+			t.Fatal("must be validation error")
+		}
+	})
+	t.Run("arguments", func(t *testing.T) {
+		args, e := StructArguments(&testOptions{})
+		if e != nil {
+			t.Fatal(e)
+		}
+		for _, name := range []string{"count", "max-retry", "db-host", "db-port", "tag"} {
+			if _, ok := args[name]; !ok {
+				t.Fatal("argument must be defined: " + name)
+			}
+		}
+		if _, ok := args["ignored"]; ok {
+			t.Fatal("ignored field must be skipped")
+		}
+		if args["mode"].Type != ArgumentTypeEnum || !args["count"].Required || args["timeout"].Type != ArgumentTypeDuration {
+			t.Fatal("wrong argument options")
+		}
+		if _, e = StructArguments(testOptions{}); e == nil {
+			t.Fatal("pointer is required")
+		}
+		if kebabCase("DBHost") != "db-host" || kebabCase("MaxRetry") != "max-retry" || kebabCase("Port2") != "port2" {
+			t.Fatal("wrong kebab case")
+		}
+	})
+}
@@ -35,7 +35,10 @@ func TestServerApp(t *testing.T) {
 	_ = os.Setenv("WEB_PORT", "8000")
 	_ = os.Setenv("WEB_HOST", "0.0.0.0")
 	app := gocli.NewApplication(environment, rootPath+"/config/yaml", &config)
-	app.ParseFlags(config.Arguments)
+	e := app.ParseFlagsFrom([]string{"-app=" + ApplicationTypeWeb}, config.Arguments)
+	if e != nil {
+		t.Fatal(e)
+	}
 
 	appType, ok := config.Arguments["app"]
 	if ok != true {
@@ -72,19 +75,25 @@ func TestServerApp(t *testing.T) {
 		app.FatalError(errors.New("incorrect host"))
 	}
 
+	router := gocli.NewCommandRouter()
+	router.Handle("exit", func(command *gocli.Command, args gocli.Arguments) porterr.IError {
+		app.SuccessMessage("Receive command: "+command.String(), command)
+		app.AttentionMessage("Exit...", command)
+		exit <- struct{}{}
+		return nil
+	})
+	router.Handle("show", func(command *gocli.Command, args gocli.Arguments) porterr.IError {
+		app.SuccessMessage("Receive command: "+command.String(), command)
+		app.AttentionMessage(gohelp.AnsiYellow+"The show is began"+gohelp.AnsiReset, command)
+		return nil
+	})
+	router.SetNotFound(func(command *gocli.Command) {
+		app.SuccessMessage("Receive command: "+command.String(), command)
+		app.AttentionMessage(gohelp.AnsiRed+"Unknown command: "+command.String()+gohelp.AnsiReset, command)
+	})
+
 	go func() {
-		err = app.Start(":3333", func(command *gocli.Command) {
-			v := command.Arguments()[0]
-			app.SuccessMessage("Receive command: "+command.String(), command)
-			if v.Name == "exit" {
-				app.AttentionMessage("Exit...", command)
-				exit <- struct{}{}
-			} else if v.Name == "show" {
-				app.AttentionMessage(gohelp.AnsiYellow+"The show is began"+gohelp.AnsiReset, command)
-			} else {
-				app.AttentionMessage(gohelp.AnsiRed+"Unknown command: "+command.String()+gohelp.AnsiReset, command)
-			}
-		})
+		err = app.Start(":3333", router.Serve)
 	}()
 	<-exit
 	app.GetLogger().Infoln("Server shutdown.")
@@ -0,0 +1,531 @@
+package gocli
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeTomlConfig Decode toml config
+// Supported: tables, arrays of tables, dotted and quoted keys, basic, literal and multi-line strings,
+// integers, floats, booleans, dates, arrays and inline tables
+// Unquoted value with ${VAR} is typed after substitution like yaml plain value. Example: port = ${PORT}
+func DecodeTomlConfig(data []byte) (*yaml.Node, error) {
+	p := &tomlParser{data: data, line: 1, tables: make(map[*yaml.Node]tomlTableKind)}
+	root := &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1}
+	table := root
+	var empty = true
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			break
+		}
+		empty = false
+		var err error
+		if p.peek() == '[' {
+			table, err = p.parseTable(root)
+		} else {
+			err = p.parseKeyValue(table)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+			return nil, p.error("expected new line after value")
+		}
+	}
+	if empty {
+		return nil, nil
+	}
+	return root, nil
+}
+
+// tomlParser toml reader with position
+type tomlParser struct {
+	// config content
+	data []byte
+	// current offset
+	pos int
+	// current line
+	line int
+	// offset of current line start
+	lineStart int
+	// how tables are created to report invalid redefinition. Root and array items are not registered
+	tables map[*yaml.Node]tomlTableKind
+}
+
+// tomlTableKind how toml table is created
+type tomlTableKind int
+
+const (
+	// tomlTableDefined table defined by [table] header or array of tables item
+	tomlTableDefined tomlTableKind = iota
+	// tomlTableImplicit parent of [a.table] header. May be defined by header once
+	tomlTableImplicit
+	// tomlTableDotted table created by dotted key. Extended by dotted keys and sub table headers only
+	tomlTableDotted
+	// tomlTableInline inline table. Can not be extended
+	tomlTableInline
+)
+
+// eof check if content is read
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// peek current char
+func (p *tomlParser) peek() byte {
+	return p.data[p.pos]
+}
+
+// next move to next char with line tracking
+func (p *tomlParser) next() {
+	if p.data[p.pos] == '\n' {
+		p.line++
+		p.lineStart = p.pos + 1
+	}
+	p.pos++
+}
+
+// column of current char
+func (p *tomlParser) column() int {
+	return p.pos - p.lineStart + 1
+}
+
+// error syntax error at current char
+func (p *tomlParser) error(message string) error {
+	return &tomlError{line: p.line, column: p.column(), message: message}
+}
+
+// tomlError toml syntax error with position
+type tomlError struct {
+	line    int
+	column  int
+	message string
+}
+
+// Error render error with line and column. Example: line 3:9: message
+func (e *tomlError) Error() string {
+	return "line " + strconv.Itoa(e.line) + ":" + strconv.Itoa(e.column) + ": " + e.message
+}
+
+// tomlErrorAt syntax error at node position
+func tomlErrorAt(node *yaml.Node, message string) error {
+	return &tomlError{line: node.Line, column: node.Column, message: message}
+}
+
+// skipSpace skip spaces and comment. New lines are skipped if multiline
+func (p *tomlParser) skipSpace(multiline bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t':
+			p.next()
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		case multiline && (c == '\n' || c == '\r'):
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// expect skip char or return error
+func (p *tomlParser) expect(c byte) error {
+	if p.eof() || p.peek() != c {
+		return p.error("expected " + strconv.QuoteRune(rune(c)))
+	}
+	p.next()
+	return nil
+}
+
+// parseTable parse [table] or [[array]] header and get table node
+func (p *tomlParser) parseTable(root *yaml.Node) (*yaml.Node, error) {
+	p.next()
+	array := !p.eof() && p.peek() == '['
+	if array {
+		p.next()
+	}
+	p.skipSpace(false)
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	if err = p.expect(']'); err != nil {
+		return nil, err
+	}
+	if array {
+		if err = p.expect(']'); err != nil {
+			return nil, err
+		}
+	}
+	table, err := p.table(root, keys[:len(keys)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	k := keyIndex(table, last.Value)
+	item := &yaml.Node{Kind: yaml.MappingNode, Line: last.Line, Column: last.Column}
+	switch {
+	case !array && k < 0:
+		table.Content = append(table.Content, last, item)
+		return item, nil
+	case !array:
+		// implicitly created table may be defined once
+		child := table.Content[k+1]
+		if kind, ok := p.tables[child]; ok && kind == tomlTableImplicit {
+			p.tables[child] = tomlTableDefined
+			return child, nil
+		}
+		return nil, tomlErrorAt(last, "table "+tomlKeyName(keys)+" is already defined")
+	case k < 0:
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: last.Line, Column: last.Column}
+		table.Content = append(table.Content, last, list)
+		k = len(table.Content) - 2
+	}
+	// static array is flow sequence
+	list := table.Content[k+1]
+	if list.Kind != yaml.SequenceNode || list.Style == yaml.FlowStyle {
+		return nil, tomlErrorAt(last, "key "+tomlKeyName(keys)+" is not an array of tables")
+	}
+	list.Content = append(list.Content, item)
+	return item, nil
+}
+
+// table get or create nested table by keys of header or dotted key. Last item of array of tables is used
+// Tables defined by header can not be extended by dotted keys, inline tables can not be extended at all
+func (p *tomlParser) table(table *yaml.Node, keys []*yaml.Node, dotted bool) (*yaml.Node, error) {
+	for i, key := range keys {
+		k := keyIndex(table, key.Value)
+		if k < 0 {
+			child := &yaml.Node{Kind: yaml.MappingNode, Line: key.Line, Column: key.Column}
+			p.tables[child] = tomlTableImplicit
+			if dotted {
+				p.tables[child] = tomlTableDotted
+			}
+			table.Content = append(table.Content, key, child)
+			table = child
+			continue
+		}
+		child := table.Content[k+1]
+		if child.Kind == yaml.SequenceNode && child.Style != yaml.FlowStyle && !dotted && len(child.Content) > 0 {
+			child = child.Content[len(child.Content)-1]
+		}
+		if child.Kind != yaml.MappingNode {
+			return nil, tomlErrorAt(key, "key "+tomlKeyName(keys[:i+1])+" is not a table")
+		}
+		kind, ok := p.tables[child]
+		switch {
+		case ok && kind == tomlTableInline:
+			return nil, tomlErrorAt(key, "inline table "+tomlKeyName(keys[:i+1])+" can not be extended")
+		case dotted && (!ok || kind != tomlTableDotted):
+			return nil, tomlErrorAt(key, "table "+tomlKeyName(keys[:i+1])+" is defined by header and can not be extended by dotted key")
+		}
+		table = child
+	}
+	return table, nil
+}
+
+// tomlKeyName dotted name of keys
+func tomlKeyName(keys []*yaml.Node) string {
+	names := make([]string, len(keys))
+	for i := range keys {
+		names[i] = keys[i].Value
+	}
+	return strings.Join(names, ".")
+}
+
+// parseKeyValue parse key = value into table
+func (p *tomlParser) parseKeyValue(table *yaml.Node) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if err = p.expect('='); err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	table, err = p.table(table, keys[:len(keys)-1], true)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if keyIndex(table, last.Value) >= 0 {
+		return tomlErrorAt(last, "duplicate key "+last.Value)
+	}
+	table.Content = append(table.Content, last, value)
+	return nil
+}
+
+// parseKey parse bare, quoted or dotted key
+func (p *tomlParser) parseKey() ([]*yaml.Node, error) {
+	var keys []*yaml.Node
+	for {
+		if p.eof() {
+			return nil, p.error("expected key")
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Line: p.line, Column: p.column()}
+		switch p.peek() {
+		case '"', '\'':
+			value, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			if value.Style == yaml.LiteralStyle {
+				return nil, tomlErrorAt(value, "multi-line string can not be a key")
+			}
+			key.Value = value.Value
+		default:
+			begin := p.pos
+			for !p.eof() && isTomlBareKey(p.peek()) {
+				p.next()
+			}
+			if begin == p.pos {
+				return nil, p.error("expected key")
+			}
+			key.Value = string(p.data[begin:p.pos])
+		}
+		keys = append(keys, key)
+		p.skipSpace(false)
+		if p.eof() || p.peek() != '.' {
+			return keys, nil
+		}
+		p.next()
+		p.skipSpace(false)
+	}
+}
+
+// isTomlBareKey check if char is allowed in bare key
+func isTomlBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parseValue parse string, array, inline table or bare value
+func (p *tomlParser) parseValue() (*yaml.Node, error) {
+	if p.eof() {
+		return nil, p.error("expected value")
+	}
+	line, column := p.line, p.column()
+	switch p.peek() {
+	case '"', '\'':
+		return p.parseString()
+	case '[':
+		p.next()
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Line: line, Column: column}
+		for {
+			p.skipSpace(true)
+			if p.eof() {
+				return nil, tomlErrorAt(node, "unterminated array")
+			}
+			if p.peek() == ']' {
+				p.next()
+				return node, nil
+			}
+			item, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+			p.skipSpace(true)
+			if !p.eof() && p.peek() == ',' {
+				p.next()
+			} else if p.eof() || p.peek() != ']' {
+				return nil, p.error("expected ',' or ']' in array")
+			}
+		}
+	case '{':
+		p.next()
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle, Line: line, Column: column}
+		for {
+			p.skipSpace(false)
+			if p.eof() {
+				return nil, tomlErrorAt(node, "unterminated inline table")
+			}
+			// trailing comma is not allowed
+			if p.peek() == '}' && len(node.Content) == 0 {
+				p.next()
+				p.tables[node] = tomlTableInline
+				return node, nil
+			}
+			if err := p.parseKeyValue(node); err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			switch {
+			case !p.eof() && p.peek() == ',':
+				p.next()
+			case !p.eof() && p.peek() == '}':
+				p.next()
+				p.tables[node] = tomlTableInline
+				return node, nil
+			default:
+				return nil, p.error("expected ',' or '}' in inline table")
+			}
+		}
+	}
+	return p.parseBare()
+}
+
+// parseString parse basic, literal or multi-line string
+func (p *tomlParser) parseString() (*yaml.Node, error) {
+	quote := p.peek()
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Line: p.line, Column: p.column()}
+	if quote == '\'' {
+		node.Style = yaml.SingleQuotedStyle
+	}
+	delimiter := strings.Repeat(string(quote), 3)
+	multiline := strings.HasPrefix(string(p.data[p.pos:]), delimiter)
+	if multiline {
+		p.pos += 3
+		node.Style = yaml.LiteralStyle
+		// new line after opening delimiter is trimmed
+		if strings.HasPrefix(string(p.data[p.pos:]), "\r\n") {
+			p.pos++
+		}
+		if !p.eof() && p.peek() == '\n' {
+			p.next()
+		}
+	} else {
+		delimiter = string(quote)
+		p.next()
+	}
+	var value strings.Builder
+	for {
+		if p.eof() || !multiline && p.peek() == '\n' {
+			return nil, tomlErrorAt(node, "unterminated string")
+		}
+		if strings.HasPrefix(string(p.data[p.pos:]), delimiter) {
+			p.pos += len(delimiter)
+			// one or two quotes before closing delimiter are part of multi-line string
+			for i := 0; multiline && i < 2 && !p.eof() && p.peek() == quote; i++ {
+				value.WriteByte(quote)
+				p.next()
+			}
+			break
+		}
+		c := p.peek()
+		if c != '\\' || quote == '\'' {
+			value.WriteByte(c)
+			p.next()
+			continue
+		}
+		p.next()
+		if err := p.parseEscape(&value, multiline); err != nil {
+			return nil, err
+		}
+	}
+	node.Value = value.String()
+	return node, nil
+}
+
+// parseEscape write escaped char of basic string. Line ending backslash trims following whitespaces
+func (p *tomlParser) parseEscape(value *strings.Builder, multiline bool) error {
+	if p.eof() {
+		return p.error("unterminated string")
+	}
+	c := p.peek()
+	p.next()
+	switch c {
+	case 'b':
+		value.WriteByte('\b')
+	case 't':
+		value.WriteByte('\t')
+	case 'n':
+		value.WriteByte('\n')
+	case 'f':
+		value.WriteByte('\f')
+	case 'r':
+		value.WriteByte('\r')
+	case '"', '\\':
+		value.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.data) {
+			return p.error("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.data[p.pos:p.pos+size]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.error("invalid unicode escape")
+		}
+		p.pos += size
+		value.WriteRune(rune(code))
+	case ' ', '\t', '\r', '\n':
+		if !multiline {
+			return p.error("invalid escape")
+		}
+		for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r' || p.peek() == '\n') {
+			p.next()
+		}
+	default:
+		return p.error("invalid escape \\" + string(c))
+	}
+	return nil
+}
+
+// parseBare parse integer, float, boolean, date or unquoted ${VAR} value
+func (p *tomlParser) parseBare() (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Line: p.line, Column: p.column()}
+	begin := p.pos
+	var depth int
+	for !p.eof() {
+		c := p.peek()
+		if depth == 0 && (c == ',' || c == ']' || c == '}' || c == '#' || c == '\n' || c == '\r') {
+			break
+		}
+		if c == '{' {
+			depth++
+		} else if c == '}' {
+			depth--
+		}
+		p.next()
+	}
+	raw := strings.TrimSpace(string(p.data[begin:p.pos]))
+	node.Value = raw
+	switch {
+	case raw == "":
+		return nil, p.error("expected value")
+	case strings.Contains(raw, "${"):
+		// typed after substitution
+	case raw == "true" || raw == "false":
+		node.Tag = "!!bool"
+	case raw == "inf" || raw == "+inf":
+		node.Tag, node.Value = "!!float", ".inf"
+	case raw == "-inf":
+		node.Tag, node.Value = "!!float", "-.inf"
+	case raw == "nan" || raw == "+nan" || raw == "-nan":
+		node.Tag, node.Value = "!!float", ".nan"
+	default:
+		if value, err := strconv.ParseInt(strings.TrimPrefix(raw, "+"), 0, 64); err == nil && (len(raw) < 2 || raw[0] != '0' || raw[1] < '0' || raw[1] > '9') {
+			node.Tag, node.Value = "!!int", strconv.FormatInt(value, 10)
+			break
+		}
+		if value, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil && !math.IsInf(value, 0) && !math.IsNaN(value) {
+			node.Tag, node.Value = "!!float", strconv.FormatFloat(value, 'g', -1, 64)
+			break
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02"} {
+			if _, err := time.Parse(layout, raw); err == nil {
+				node.Tag = "!!timestamp"
+				return node, nil
+			}
+		}
+		return nil, tomlErrorAt(node, "invalid value "+raw)
+	}
+	return node, nil
+}
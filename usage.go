@@ -0,0 +1,168 @@
+package gocli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dimonrus/gohelp"
+)
+
+const (
+	// UsageFormatPlain Plain text usage
+	UsageFormatPlain = "plain"
+	// UsageFormatAnsi ANSI coloured usage for terminal
+	UsageFormatAnsi = "ansi"
+	// UsageFormatMarkdown Markdown usage for docs
+	UsageFormatMarkdown = "markdown"
+)
+
+// UsageGroup named group of arguments. Usually application mode
+type UsageGroup struct {
+	// Name of group
+	Name string
+	// Description of group
+	Description string
+	// Arguments of group
+	Arguments ArgumentMap
+}
+
+// RenderUsage Render usage of argument groups in plain, ansi or markdown format
+func RenderUsage(w io.Writer, format string, title string, groups ...UsageGroup) {
+	switch format {
+	case UsageFormatMarkdown:
+		renderMarkdownUsage(w, title, groups)
+	case UsageFormatAnsi:
+		renderTextUsage(w, title, groups, true)
+	default:
+		renderTextUsage(w, title, groups, false)
+	}
+}
+
+// Usage Render usage of all application modes
+// Arguments registered by ParseFlags are rendered if application has no visible modes
+func (a *DNApp) Usage(w io.Writer, format string) {
+	groups := make([]UsageGroup, 0, len(a.modes))
+	for _, mode := range a.modes {
+		if mode.Hidden {
+			continue
+		}
+		groups = append(groups, UsageGroup{Name: mode.Name, Description: mode.Description, Arguments: mode.Arguments})
+	}
+	title := filepath.Base(os.Args[0])
+	if len(groups) > 0 {
+		title += " <mode> [arguments]"
+	} else {
+		title += " [arguments]"
+		if len(a.arguments) > 0 {
+			groups = append(groups, UsageGroup{Arguments: a.arguments})
+		}
+	}
+	RenderUsage(w, format, title, groups...)
+}
+
+// GetUsageFormat Get usage format. Default is plain
+func (a *DNApp) GetUsageFormat() string {
+	if a.usageFormat == "" {
+		return UsageFormatPlain
+	}
+	return a.usageFormat
+}
+
+// SetUsageFormat Set usage format for -h output
+func (a *DNApp) SetUsageFormat(format string) {
+	a.usageFormat = format
+}
+
+// usageType render argument type with enum values
+func usageType(argument Argument) string {
+	if argument.Type == ArgumentTypeEnum && len(argument.Enum) > 0 {
+		return argument.Type + "(" + strings.Join(argument.Enum, "|") + ")"
+	}
+	return argument.Type
+}
+
+// usageDefault render argument default value
+func usageDefault(argument Argument) string {
+	if argument.Default == nil {
+		return ""
+	}
+	def, _ := Argument{Value: argument.Default}.AsString()
+	return def
+}
+
+// renderTextUsage render plain or coloured usage
+func renderTextUsage(w io.Writer, title string, groups []UsageGroup, colored bool) {
+	paint := func(color string, s string) string {
+		if !colored || s == "" {
+			return s
+		}
+		return color + s + gohelp.AnsiReset
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "%s %s\n", paint(gohelp.AnsiBold, "Usage:"), title)
+	for _, group := range groups {
+		_, _ = fmt.Fprintln(tw)
+		if group.Name != "" {
+			header := paint(gohelp.AnsiCyan, group.Name)
+			if group.Description != "" {
+				header += " - " + group.Description
+			}
+			_, _ = fmt.Fprintln(tw, header)
+		}
+		for _, key := range group.Arguments.keys() {
+			argument := group.Arguments[key]
+			var extra []string
+			if argument.Required {
+				extra = append(extra, paint(gohelp.AnsiRed, "(required)"))
+			}
+			if def := usageDefault(argument); def != "" {
+				extra = append(extra, paint(gohelp.AnsiBlue, "(default: "+def+")"))
+			}
+			if env := argument.Env; env != "" {
+				extra = append(extra, paint(gohelp.AnsiMagenta, "[env: "+env+"]"))
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n",
+				paint(gohelp.AnsiGreen, "-"+key),
+				paint(gohelp.AnsiYellow, usageType(argument)),
+				strings.TrimSpace(argument.Label+" "+strings.Join(extra, " ")))
+		}
+	}
+	_ = tw.Flush()
+}
+
+// renderMarkdownUsage render usage as markdown tables
+func renderMarkdownUsage(w io.Writer, title string, groups []UsageGroup) {
+	escape := strings.NewReplacer("|", "\\|", "\n", " ")
+	_, _ = fmt.Fprintf(w, "# Usage\n\n`%s`\n", title)
+	for _, group := range groups {
+		if group.Name != "" {
+			_, _ = fmt.Fprintf(w, "\n## %s\n", group.Name)
+		}
+		if group.Description != "" {
+			_, _ = fmt.Fprintf(w, "\n%s\n", escape.Replace(group.Description))
+		}
+		if len(group.Arguments) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprint(w, "\n| Argument | Type | Required | Default | Env | Description |\n|---|---|---|---|---|---|\n")
+		for _, key := range group.Arguments.keys() {
+			argument := group.Arguments[key]
+			var required, def, env string
+			if argument.Required {
+				required = "yes"
+			}
+			if d := usageDefault(argument); d != "" {
+				def = "`" + escape.Replace(d) + "`"
+			}
+			if argument.Env != "" {
+				env = "`" + argument.Env + "`"
+			}
+			_, _ = fmt.Fprintf(w, "| `-%s` | %s | %s | %s | %s | %s |\n",
+				key, escape.Replace(usageType(argument)), required, def, env, escape.Replace(argument.Label))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package gocli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dimonrus/gohelp"
+)
+
+func TestRenderUsage(t *testing.T) {
+	group := UsageGroup{
+		Name:        "consumer",
+		Description: "Start consumer",
+		Arguments: ArgumentMap{
+			"name":  {Type: ArgumentTypeString, Label: "consumer name", Required: true, Env: "CONSUMER_NAME"},
+			"count": {Type: ArgumentTypeInt, Label: "count", Default: 10},
+			"mode":  {Type: ArgumentTypeEnum, Label: "run mode", Enum: []string{"sync", "async"}},
+		},
+	}
+	t.Run("plain", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		RenderUsage(buf, UsageFormatPlain, "service <mode>", group)
+		for _, s := range []string{"Usage: service <mode>", "consumer - Start consumer", "-name", "(required)", "(default: 10)", "[env: CONSUMER_NAME]", "enum(sync|async)"} {
+			if !strings.Contains(buf.String(), s) {
+				t.Fatal("usage must contain " + s)
+			}
+		}
+		if strings.Contains(buf.String(), "\x1b[") {
+			t.Fatal("plain usage must not be coloured")
+		}
+	})
+	t.Run("ansi", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		RenderUsage(buf, UsageFormatAnsi, "service <mode>", group)
+		if !strings.Contains(buf.String(), gohelp.AnsiRed+"(required)"+gohelp.AnsiReset) {
+			t.Fatal("ansi usage must be coloured")
+		}
+	})
+	t.Run("markdown", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		RenderUsage(buf, UsageFormatMarkdown, "service <mode>", group)
+		for _, s := range []string{"## consumer", "| `-name` | string | yes |  | `CONSUMER_NAME` | consumer name |", "| `-count` | int |  | `10` |", "enum(sync\\|async)"} {
+			if !strings.Contains(buf.String(), s) {
+				t.Fatal("markdown usage must contain " + s + "\n" + buf.String())
+			}
+		}
+	})
+	t.Run("help", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		app := &DNApp{}
+		app.SetOutput(buf)
+		app.AddMode(Mode{Name: group.Name, Description: group.Description, Arguments: group.Arguments})
+		if e := app.RunFrom([]string{"consumer", "-h"}); e == nil {
+			t.Fatal("help must stop parsing")
+		}
+		if !strings.Contains(buf.String(), "consumer [arguments]") || !strings.Contains(buf.String(), "(default: 10)") {
+			t.Fatal("mode usage must be rendered", buf.String())
+		}
+	})
+	t.Run("arguments", func(t *testing.T) {
+		app := &DNApp{}
+		e := app.ParseFlagsFrom([]string{"-name=orders"}, ArgumentMap{
+			"name":  group.Arguments["name"],
+			"count": group.Arguments["count"],
+		})
+		if e != nil {
+			t.Fatal(e)
+		}
+		buf := bytes.NewBuffer(nil)
+		app.Usage(buf, UsageFormatPlain)
+		for _, s := range []string{"[arguments]", "-name", "consumer name", "(default: 10)"} {
+			if !strings.Contains(buf.String(), s) {
+				t.Fatal("usage of arguments must contain "+s, buf.String())
+			}
+		}
+		if strings.Contains(buf.String(), "<mode>") {
+			t.Fatal("usage without modes must not contain mode", buf.String())
+		}
+	})
+}
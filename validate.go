@@ -0,0 +1,241 @@
+package gocli
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dimonrus/porterr"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// TagValidate Struct tag with config validation rules. Example: validate:"required,min=1,max=65535"
+	TagValidate = "validate"
+	// TagYaml Struct tag with yaml key name
+	TagYaml = "yaml"
+
+	// ValidateRequired Value must not be zero
+	ValidateRequired = "required"
+	// ValidateMin Min value, length or items count
+	ValidateMin = "min"
+	// ValidateMax Max value, length or items count
+	ValidateMax = "max"
+)
+
+// SetStrictConfig Enable errors for config keys not defined in config struct
+// Strict mode is applied on next ParseConfig or reload. Use WithStrictConfig to apply it on first parse
+func (a *DNApp) SetStrictConfig(strict bool) {
+	a.config.strict = strict
+}
+
+// WithStrictConfig Enable errors for config keys not defined in config struct before config is parsed
+func WithStrictConfig(strict bool) ApplicationOption {
+	return func(app *DNApp) {
+		app.SetStrictConfig(strict)
+	}
+}
+
+// ValidateConfig Validate struct fields according to validate tags
+// All violations are returned as error details named by yaml key path
+func ValidateConfig(v interface{}) porterr.IError {
+	e := porterr.New(porterr.PortErrorValidation, "Config is invalid")
+	validateValue(reflect.ValueOf(v), "", e)
+	return e.IfDetails()
+}
+
+// validateValue validate fields of struct value recursively
+func validateValue(rv reflect.Value, path string, e porterr.IError) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		for _, field := range yamlFields(rv.Type()) {
+			value := rv.FieldByIndex(field.index)
+			name := path
+			if field.name != "" {
+				name = joinKey(path, field.name)
+			}
+			if rules := field.tag.Get(TagValidate); rules != "" {
+				for _, message := range validateRules(value, rules) {
+					e.PushDetail(porterr.PortErrorArgument, name, message)
+				}
+			}
+			validateValue(value, name, e)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			validateValue(rv.Index(i), path+"["+strconv.Itoa(i)+"]", e)
+		}
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), joinKey(path, reflectString(iter.Key())), e)
+		}
+	}
+}
+
+// validateRules check value by comma separated rules. Returns violation messages
+func validateRules(value reflect.Value, rules string) (messages []string) {
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case ValidateRequired:
+			if value.IsZero() {
+				messages = append(messages, "is required")
+			}
+		case ValidateMin, ValidateMax:
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				messages = append(messages, "rule "+rule+" is invalid")
+				continue
+			}
+			measure, ok := validateMeasure(value)
+			if !ok {
+				messages = append(messages, "rule "+rule+" is not supported for "+value.Type().String())
+				continue
+			}
+			if name == ValidateMin && measure < limit {
+				messages = append(messages, "must be greater than or equal to "+formatFloat(limit))
+			}
+			if name == ValidateMax && measure > limit {
+				messages = append(messages, "must be less than or equal to "+formatFloat(limit))
+			}
+		default:
+			messages = append(messages, "rule "+name+" is unknown")
+		}
+	}
+	return
+}
+
+// validateMeasure numeric value, string length or items count
+func validateMeasure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true
+	case reflect.Pointer:
+		if value.IsNil() {
+			return 0, true
+		}
+		return validateMeasure(value.Elem())
+	}
+	return 0, false
+}
+
+// unknownKeys push error detail for each mapping key not defined in type
+// Detail is named by file position. Example: config/local.yaml:3:3
+func unknownKeys(node *yaml.Node, t reflect.Type, path string, file string, e porterr.IError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node == nil || reflect.PointerTo(t).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) {
+		return
+	}
+	switch {
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Type)
+		for _, field := range yamlFields(t) {
+			fields[field.name] = field.typ
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+			name := joinKey(path, key.Value)
+			ft, ok := fields[key.Value]
+			if !ok {
+				ft, ok = fields[""]
+				if ok {
+					ft = ft.Elem()
+				}
+			}
+			if !ok {
+				position := file + ":" + strconv.Itoa(key.Line) + ":" + strconv.Itoa(key.Column)
+				e.PushDetail(porterr.PortErrorArgument, position, "unknown key "+name)
+				continue
+			}
+			unknownKeys(value, ft, name, file, e)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			unknownKeys(node.Content[i+1], t.Elem(), joinKey(path, node.Content[i].Value), file, e)
+		}
+	case node.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for i, item := range node.Content {
+			unknownKeys(item, t.Elem(), path+"["+strconv.Itoa(i)+"]", file, e)
+		}
+	}
+}
+
+// yamlField struct field decoded from yaml key
+type yamlField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+	tag   reflect.StructTag
+}
+
+// yamlFields fields of struct by yaml key names. Inline fields are flattened
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get(TagYaml), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			switch field.Type.Kind() {
+			case reflect.Struct:
+				for _, inner := range yamlFields(field.Type) {
+					inner.index = append([]int{i}, inner.index...)
+					fields = append(fields, inner)
+				}
+			case reflect.Map:
+				// inline map holds all keys not defined in struct
+				fields = append(fields, yamlField{index: []int{i}, typ: field.Type, tag: field.Tag})
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields = append(fields, yamlField{name: name, index: []int{i}, typ: field.Type, tag: field.Tag})
+	}
+	return fields
+}
+
+// joinKey join yaml key path with dot
+func joinKey(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// reflectString string presentation of map key
+func reflectString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,89 @@
+package gocli
+
+import (
+	"strings"
+	"testing"
+)
+
+type testValidateConfig struct {
+	Project struct {
+		Name string `validate:"required"`
+	}
+	Web struct {
+		Port int `validate:"required,min=1,max=65535"`
+	}
+	Hosts   []string          `validate:"min=1"`
+	Extra   map[string]string `yaml:"extra"`
+	Servers []struct {
+		Host string `yaml:"address" validate:"required"`
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg := testValidateConfig{}
+		cfg.Project.Name = "dna"
+		cfg.Web.Port = 8080
+		cfg.Hosts = []string{"localhost"}
+		if e := ValidateConfig(&cfg); e != nil {
+			t.Fatal(e)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		cfg := testValidateConfig{}
+		cfg.Web.Port = 70000
+		cfg.Servers = append(cfg.Servers, struct {
+			Host string `yaml:"address" validate:"required"`
+		}{})
+		e := ValidateConfig(&cfg)
+		if e == nil || len(e.GetDetails()) != 4 {
+			t.Fatal("must be 4 violations", e)
+		}
+		names := make([]string, 0)
+		for _, detail := range e.GetDetails() {
+			names = append(names, detail.Origin().Name)
+		}
+		if strings.Join(names, ",") != "project.name,web.port,hosts,servers[0].address" {
+			t.Fatal("wrong names", names)
+		}
+	})
+	t.Run("rule", func(t *testing.T) {
+		var cfg struct {
+			Name string `validate:"unique"`
+		}
+		if e := ValidateConfig(&cfg); e == nil {
+			t.Fatal("unknown rule must be error")
+		}
+	})
+}
+
+func TestDNApp_SetStrictConfig(t *testing.T) {
+	dir := writeTestConfigs(t, map[string]string{
+		"global.yaml": "project:\n  name: dna\n  titel: typo\nweb:\n  port: 8080\nhosts: [localhost]\nextra:\n  any: value\n",
+		"local.yaml":  "depends: global\nweb:\n  prot: 9000\n  port: 0\nservers:\n  - address: localhost\n    ip: 127.0.0.1\n",
+	})
+	t.Run("strict", func(t *testing.T) {
+		var cfg testValidateConfig
+		_, e := NewApplicationE("local", dir, &cfg, WithStrictConfig(true))
+		if e == nil || len(e.GetDetails()) != 5 {
+			t.Fatal("must be 5 problems", e)
+		}
+		details := e.GetDetails()
+		if !strings.HasSuffix(details[0].Origin().Name, "global.yaml:3:3") || details[0].Error() != "unknown key project.titel" {
+			t.Fatal("wrong unknown key", details[0].Origin().Name, details[0])
+		}
+		if !strings.HasSuffix(details[1].Origin().Name, "local.yaml:3:3") || details[2].Error() != "unknown key servers[0].ip" {
+			t.Fatal("wrong unknown key", details[1].Origin().Name, details[2])
+		}
+		if details[3].Origin().Name != "web.port" {
+			t.Fatal("wrong validation", details[3].Origin().Name)
+		}
+	})
+	t.Run("not_strict", func(t *testing.T) {
+		var cfg testValidateConfig
+		_, e := NewApplicationE("local", dir, &cfg)
+		if e == nil || len(e.GetDetails()) != 2 {
+			t.Fatal("must be 2 problems", e)
+		}
+	})
+}
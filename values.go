@@ -0,0 +1,224 @@
+package gocli
+
+import (
+	"flag"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimonrus/gohelp"
+	"github.com/dimonrus/porterr"
+)
+
+const (
+	// ArgumentListDelimiter Delimiter for list and map items
+	ArgumentListDelimiter = ","
+	// ArgumentMapAssignee Delimiter for map key and value
+	ArgumentMapAssignee = "="
+)
+
+// newArgumentValue create flag value with storage for extended argument types
+func newArgumentValue(argument Argument) (flag.Value, interface{}, porterr.IError) {
+	switch argument.Type {
+	case ArgumentTypeDuration:
+		var value time.Duration
+		return (*durationValue)(&value), &value, nil
+	case ArgumentTypeTime:
+		var value time.Time
+		return (*timeValue)(&value), &value, nil
+	case ArgumentTypeStringSlice:
+		var value []string
+		return &stringSliceValue{value: &value}, &value, nil
+	case ArgumentTypeIntSlice:
+		var value []int64
+		return &intSliceValue{value: &value}, &value, nil
+	case ArgumentTypeMap:
+		var value map[string]string
+		return &mapValue{value: &value}, &value, nil
+	case ArgumentTypeEnum:
+		if len(argument.Enum) == 0 {
+			return nil, nil, porterr.New(porterr.PortErrorArgument, "enum values are required. Argument: "+argument.Label)
+		}
+		var value string
+		return &enumValue{value: &value, enum: argument.Enum}, &value, nil
+	}
+	return nil, nil, porterr.New(porterr.PortErrorArgument, "argument type: "+argument.Type+" is not supported. Argument: "+argument.Label)
+}
+
+// durationValue time.Duration flag value
+type durationValue time.Duration
+
+// Set parse duration
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+// String render duration
+func (d *durationValue) String() string {
+	return (*time.Duration)(d).String()
+}
+
+// timeValue RFC 3339 time flag value
+type timeValue time.Time
+
+// Set parse RFC 3339 time
+func (t *timeValue) Set(s string) error {
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	*t = timeValue(v)
+	return nil
+}
+
+// String render time
+func (t *timeValue) String() string {
+	if t == nil || time.Time(*t).IsZero() {
+		return ""
+	}
+	return time.Time(*t).Format(time.RFC3339)
+}
+
+// stringSliceValue repeatable or comma separated string list
+type stringSliceValue struct {
+	value *[]string
+}
+
+// Set append items
+func (s *stringSliceValue) Set(v string) error {
+	*s.value = append(*s.value, splitList(v)...)
+	return nil
+}
+
+// String render list
+func (s *stringSliceValue) String() string {
+	if s == nil || s.value == nil {
+		return ""
+	}
+	return strings.Join(*s.value, ArgumentListDelimiter)
+}
+
+// intSliceValue repeatable or comma separated int list
+type intSliceValue struct {
+	value *[]int64
+}
+
+// Set append items
+func (s *intSliceValue) Set(v string) error {
+	for _, item := range splitList(v) {
+		i, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return err
+		}
+		*s.value = append(*s.value, i)
+	}
+	return nil
+}
+
+// String render list
+func (s *intSliceValue) String() string {
+	if s == nil || s.value == nil {
+		return ""
+	}
+	items := make([]string, len(*s.value))
+	for i, v := range *s.value {
+		items[i] = strconv.FormatInt(v, 10)
+	}
+	return strings.Join(items, ArgumentListDelimiter)
+}
+
+// mapValue repeatable or comma separated key=value pairs
+type mapValue struct {
+	value *map[string]string
+}
+
+// Set add pairs
+func (m *mapValue) Set(v string) error {
+	pairs, err := parseMap(v)
+	if err != nil {
+		return err
+	}
+	if *m.value == nil {
+		*m.value = make(map[string]string, len(pairs))
+	}
+	for key, value := range pairs {
+		(*m.value)[key] = value
+	}
+	return nil
+}
+
+// String render pairs
+func (m *mapValue) String() string {
+	if m == nil || m.value == nil {
+		return ""
+	}
+	return renderMap(*m.value)
+}
+
+// enumValue string with allowed values
+type enumValue struct {
+	value *string
+	enum  []string
+}
+
+// Set check and set value
+func (e *enumValue) Set(v string) error {
+	if !gohelp.ExistsInArray(v, e.enum) {
+		return porterr.NewF(porterr.PortErrorArgument, "%s is not one of: %s", v, strings.Join(e.enum, ", "))
+	}
+	*e.value = v
+	return nil
+}
+
+// String render value
+func (e *enumValue) String() string {
+	if e == nil || e.value == nil {
+		return ""
+	}
+	return *e.value
+}
+
+// splitList split comma separated items
+func splitList(v string) []string {
+	items := strings.Split(v, ArgumentListDelimiter)
+	result := items[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseMap parse k=v,k2=v2 pairs
+func parseMap(v string) (map[string]string, porterr.IError) {
+	items := splitList(v)
+	result := make(map[string]string, len(items))
+	for _, item := range items {
+		key, value, ok := strings.Cut(item, ArgumentMapAssignee)
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, porterr.NewF(porterr.PortErrorArgument, "%s is not key%svalue pair", item, ArgumentMapAssignee)
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
+// renderMap render pairs sorted by key
+func renderMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		keys[i] = key + ArgumentMapAssignee + m[key]
+	}
+	return strings.Join(keys, ArgumentListDelimiter)
+}
@@ -0,0 +1,87 @@
+package gocli
+
+import (
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestNewArgumentValue(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	args := ArgumentMap{
+		"timeout": {Type: ArgumentTypeDuration},
+		"since":   {Type: ArgumentTypeTime},
+		"tags":    {Type: ArgumentTypeStringSlice},
+		"ids":     {Type: ArgumentTypeIntSlice},
+		"labels":  {Type: ArgumentTypeMap},
+		"app":     {Type: ArgumentTypeEnum, Enum: []string{"web", "script"}},
+	}
+	for key, argument := range args {
+		value, pointer, e := newArgumentValue(argument)
+		if e != nil {
+			t.Fatal(e)
+		}
+		argument.Value = pointer
+		args[key] = argument
+		set.Var(value, key, argument.Label)
+	}
+	err := set.Parse([]string{
+		"-timeout=1m30s", "-since=2024-05-01T10:00:00Z",
+		"-tags=a,b", "-tags", "c", "-ids=1,2", "-labels=env=prod,zone=a", "-labels", "zone=b", "-app=web",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args["timeout"].DurationOr(0) != time.Second*90 {
+		t.Fatal("wrong duration")
+	}
+	if args["since"].TimeOr(time.Time{}).Year() != 2024 {
+		t.Fatal("wrong time")
+	}
+	if tags := MustGet[[]string](args, "tags"); len(tags) != 3 || tags[2] != "c" {
+		t.Fatal("wrong string list", tags)
+	}
+	if ids := MustGet[[]int64](args, "ids"); len(ids) != 2 || ids[1] != 2 {
+		t.Fatal("wrong int list")
+	}
+	if labels := MustGet[map[string]string](args, "labels"); labels["env"] != "prod" || labels["zone"] != "b" {
+		t.Fatal("wrong map", labels)
+	}
+	if args["labels"].StringOr("") != "env=prod,zone=b" {
+		t.Fatal("wrong map render")
+	}
+	if args["app"].StringOr("") != "web" {
+		t.Fatal("wrong enum")
+	}
+	if err = set.Parse([]string{"-app=consumer"}); err == nil {
+		t.Fatal("enum must be checked")
+	}
+	if _, _, e := newArgumentValue(Argument{Type: ArgumentTypeEnum}); e == nil {
+		t.Fatal("enum values are required")
+	}
+	if _, _, e := newArgumentValue(Argument{Type: "complex"}); e == nil {
+		t.Fatal("unknown type must be error")
+	}
+}
+
+func TestArgument_AsExtended(t *testing.T) {
+	timeout, e := Argument{Value: "5s"}.AsDuration()
+	if e != nil || timeout != time.Second*5 {
+		t.Fatal("duration string must be parsed")
+	}
+	ids, e := Argument{Value: "1, 2,3"}.AsInts()
+	if e != nil || len(ids) != 3 {
+		t.Fatal("int list string must be parsed")
+	}
+	if _, e = (Argument{Value: "a=1,b"}).AsMap(); e == nil {
+		t.Fatal("wrong pair must be error")
+	}
+	command := ParseCommand([]byte("consumer wait 150ms"))
+	if command.Arguments()[2].Type != ArgumentTypeDuration || command.Arguments()[2].DurationOr(0) != time.Millisecond*150 {
+		t.Fatal("duration must be inferred")
+	}
+	value, e := Get[time.Duration](ArgumentMap{"timeout": {Value: "2m"}}, "timeout")
+	if e != nil || value != time.Minute*2 {
+		t.Fatal("generic duration must be parsed")
+	}
+}
@@ -0,0 +1,196 @@
+package gocli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/dimonrus/porterr"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigWatchInterval Default interval of config files polling
+const ConfigWatchInterval = time.Second
+
+// ConfigChangeHandler Handler of config reload. Changed contains top level keys with changed values
+type ConfigChangeHandler func(old interface{}, new interface{}, changed []string)
+
+// configFileState state of watched config file
+type configFileState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// GetWatchInterval Get interval of config files polling
+func (a *DNApp) GetWatchInterval() time.Duration {
+	if a.watchInterval <= 0 {
+		return ConfigWatchInterval
+	}
+	return a.watchInterval
+}
+
+// SetWatchInterval Set interval of config files polling
+func (a *DNApp) SetWatchInterval(interval time.Duration) {
+	a.watchInterval = interval
+}
+
+// WatchConfig Poll env config file and its depends chain until context is done
+// On change config is parsed into fresh struct and swapped if it parses and validates
+// Struct passed to NewApplication is not updated, use GetConfig or new value of callback
+func (a *DNApp) WatchConfig(ctx context.Context, callback ConfigChangeHandler) porterr.IError {
+	a.configMutex.RLock()
+	env, files := a.config.env, a.config.files
+	a.configMutex.RUnlock()
+	if env == "" {
+		return porterr.New(porterr.PortErrorArgument, "Config is not parsed")
+	}
+	states, _ := pollConfigFiles(files, nil)
+	ticker := time.NewTicker(a.GetWatchInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		var modified bool
+		states, modified = pollConfigFiles(files, states)
+		if !modified {
+			continue
+		}
+		old, values, changed, e := a.reloadConfig()
+		if e != nil {
+			a.FailMessage("Config reload failed: " + e.Error())
+			for _, detail := range e.GetDetails() {
+				a.FailMessage(detail.Origin().Name + ": " + detail.Error())
+			}
+			continue
+		}
+		a.configMutex.RLock()
+		files = a.config.files
+		a.configMutex.RUnlock()
+		// depends chain may change after reload. Only new files are polled
+		// to keep changes made while config was reloaded for next poll
+		var added []string
+		for _, file := range files {
+			if _, ok := states[file]; !ok {
+				added = append(added, file)
+			}
+		}
+		addedStates, _ := pollConfigFiles(added, nil)
+		for file, state := range addedStates {
+			states[file] = state
+		}
+		if len(changed) > 0 && callback != nil {
+			callback(old, values, changed)
+		}
+	}
+}
+
+// reloadConfig parse config into fresh struct and swap current config
+func (a *DNApp) reloadConfig() (old interface{}, values interface{}, changed []string, e porterr.IError) {
+	a.configMutex.RLock()
+	current := a.config
+	a.configMutex.RUnlock()
+	t := reflect.TypeOf(current.values)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return nil, nil, nil, porterr.New(porterr.PortErrorType, "Config must be a pointer")
+	}
+	next := a.configParser(current, reflect.New(t.Elem()).Interface())
+	e = next.parseConfig(current.env, nil)
+	if e != nil {
+		return nil, nil, nil, e
+	}
+	a.swapConfig(next)
+	return current.values, next.config.values, changedKeys(current.node, next.config.node), nil
+}
+
+// configParser application to parse config of current source and options into values
+// Config is parsed without lock and swapped with swapConfig
+func (a *DNApp) configParser(current config, values interface{}) *DNApp {
+	return &DNApp{
+		config: config{
+			values:   values,
+			path:     current.path,
+			fsys:     current.fsys,
+			override: current.override,
+			strict:   current.strict,
+			keyEnv:   current.keyEnv,
+			keyFile:  current.keyFile,
+		},
+		logger:           a.logger,
+		mergeStrategies:  a.mergeStrategies,
+		configDecoders:   a.configDecoders,
+		configExtensions: a.configExtensions,
+	}
+}
+
+// swapConfig set config parsed by configParser
+func (a *DNApp) swapConfig(next *DNApp) {
+	a.configMutex.Lock()
+	a.config.values = next.config.values
+	a.config.env = next.config.env
+	a.config.node = next.config.node
+	a.config.masked = next.config.masked
+	a.config.files = next.config.files
+	a.config.secrets = next.config.secrets
+	a.configMutex.Unlock()
+}
+
+// pollConfigFiles stat config files and hash files with changed modification time or size
+// Missing files have zero state. Returns new states and true if content of any file changed
+func pollConfigFiles(files []string, states map[string]configFileState) (map[string]configFileState, bool) {
+	var changed bool
+	next := make(map[string]configFileState, len(files))
+	for _, file := range files {
+		var state configFileState
+		if info, err := os.Stat(file); err == nil {
+			state.modTime, state.size = info.ModTime(), info.Size()
+		}
+		prev, ok := states[file]
+		if ok && prev.modTime.Equal(state.modTime) && prev.size == state.size {
+			next[file] = prev
+			continue
+		}
+		if data, err := os.ReadFile(file); err == nil {
+			state.hash = sha256.Sum256(data)
+		}
+		next[file] = state
+		changed = changed || !ok || prev.hash != state.hash
+	}
+	return next, changed
+}
+
+// changedKeys sorted top level keys with different values
+func changedKeys(old *yaml.Node, new *yaml.Node) []string {
+	values := func(node *yaml.Node) map[string][]byte {
+		result := make(map[string][]byte)
+		if node == nil || node.Kind != yaml.MappingNode {
+			return result
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			data, _ := yaml.Marshal(node.Content[i+1])
+			result[node.Content[i].Value] = data
+		}
+		return result
+	}
+	before, after := values(old), values(new)
+	var changed []string
+	for key, value := range after {
+		if prev, ok := before[key]; !ok || !bytes.Equal(prev, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
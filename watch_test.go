@@ -0,0 +1,123 @@
+package gocli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDNApp_WatchConfig(t *testing.T) {
+	dir := writeTestConfigs(t, map[string]string{
+		"global.yaml": "project:\n  name: dna\nweb:\n  port: 8080\n",
+		"local.yaml":  "depends: global\nproject:\n  debug: true\n",
+	})
+	var cfg testValidateConfig
+	app := &DNApp{config: config{values: &cfg, path: dir}, logger: NewLogger(LoggerConfig{})}
+	app.SetWatchInterval(time.Millisecond * 10)
+	if e := app.ParseConfigE("local"); e == nil || len(e.GetDetails()) != 1 {
+		t.Fatal("hosts must be invalid", e)
+	}
+	type change struct {
+		old, new interface{}
+		keys     []string
+	}
+	changes := make(chan change, 1)
+	// edit called while config is reloaded
+	edits := make(chan func(), 1)
+	app.SetConfigDecoder(ConfigExtensionYaml, ConfigDecoderFunc(func(data []byte) (*yaml.Node, error) {
+		select {
+		case edit := <-edits:
+			edit()
+		default:
+		}
+		return DecodeYamlConfig(data)
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if e := app.WatchConfig(ctx, func(old, new interface{}, changed []string) {
+			changes <- change{old: old, new: new, keys: changed}
+		}); e != nil {
+			t.Error(e)
+		}
+	}()
+	var writes int
+	write := func(name, content string) {
+		// modification time may be equal on fast file systems
+		writes++
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		future := time.Now().Add(time.Second * time.Duration(writes))
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Run("invalid", func(t *testing.T) {
+		write("global.yaml", "project:\n  name: dna\nweb:\n  port: 0\nhosts: [a]\n")
+		select {
+		case c := <-changes:
+			t.Fatal("invalid config must not be applied", c.keys)
+		case <-time.After(time.Millisecond * 100):
+		}
+		if app.GetConfig() != &cfg {
+			t.Fatal("config must not be swapped")
+		}
+	})
+	t.Run("depends", func(t *testing.T) {
+		write("global.yaml", "project:\n  name: dna\nweb:\n  port: 9000\nhosts: [a]\n")
+		select {
+		case c := <-changes:
+			if strings.Join(c.keys, ",") != "hosts,web" {
+				t.Fatal("wrong changed keys", c.keys)
+			}
+			if c.old != &cfg || c.new.(*testValidateConfig).Web.Port != 9000 {
+				t.Fatal("wrong values")
+			}
+			if app.GetConfig() != c.new || cfg.Web.Port != 8080 {
+				t.Fatal("config must be swapped")
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatal("change must be reported")
+		}
+	})
+	t.Run("env", func(t *testing.T) {
+		write("local.yaml", "depends: global\nproject:\n  debug: false\n  name: local\n")
+		select {
+		case c := <-changes:
+			if strings.Join(c.keys, ",") != "project" {
+				t.Fatal("wrong changed keys", c.keys)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatal("change must be reported")
+		}
+	})
+	t.Run("during_reload", func(t *testing.T) {
+		edits <- func() {
+			path := filepath.Join(dir, "local.yaml")
+			_ = os.WriteFile(path, []byte("depends: global\nproject:\n  name: edited\n"), 0644)
+			future := time.Now().Add(time.Hour)
+			_ = os.Chtimes(path, future, future)
+		}
+		write("local.yaml", "depends: global\nproject:\n  name: reloaded\n")
+		for _, name := range []string{"reloaded", "edited"} {
+			select {
+			case c := <-changes:
+				if c.new.(*testValidateConfig).Project.Name != name {
+					t.Fatal("wrong reloaded config", c.new.(*testValidateConfig).Project.Name, name)
+				}
+			case <-time.After(time.Second * 2):
+				t.Fatal("change must be reported", name)
+			}
+		}
+	})
+	cancel()
+	<-done
+}